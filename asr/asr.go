@@ -1 +1,85 @@
 package asr
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Recognizer 语音识别引擎的统一接口
+//
+// paraformer.Engine 与 whisper.Engine 均实现了该接口，调用方可以通过配置切换引擎而无需修改调用代码
+type Recognizer interface {
+	// Transcribe 对 float32 音频样本数据进行识别
+	//
+	// samples: 采样率为 16KHz 的单声道音频数据，范围 [-1, 1]
+	Transcribe(samples []float32, opt ...TranscribeOption) (*Result, error)
+	// TranscribeContext 与 Transcribe 相同，ctx 取消或超时时尽快中止并返回 ctx.Err()
+	TranscribeContext(ctx context.Context, samples []float32, opt ...TranscribeOption) (*Result, error)
+	// TranscribeBytes 读取音频字节流 (WAV、FLAC、MP3) 并进行识别
+	TranscribeBytes(wavBytes []byte, opt ...TranscribeOption) (*Result, error)
+	// TranscribeReader 从音频流增量读取并识别，内存占用与音频总时长无关
+	TranscribeReader(r io.Reader, format AudioFormat, opt ...TranscribeOption) (*Result, error)
+	// TranscribeReaderContext 与 TranscribeReader 相同，ctx 取消或超时时在片段之间或片段内中止
+	TranscribeReaderContext(ctx context.Context, r io.Reader, format AudioFormat, opt ...TranscribeOption) (*Result, error)
+	// TranscribeFile 读取音频文件并进行识别
+	TranscribeFile(wavPath string, opt ...TranscribeOption) (*Result, error)
+	// TranscribeLong 按语音段切分任意时长的音频后逐段识别，合并为带时间范围的结果
+	TranscribeLong(samples []float32, opt ...LongOption) (*Result, error)
+	// TranscribeLongContext 与 TranscribeLong 相同，ctx 取消或超时时在语音段之间或语音段内中止
+	TranscribeLongContext(ctx context.Context, samples []float32, opt ...LongOption) (*Result, error)
+	// Close 释放引擎持有的资源
+	Close() error
+}
+
+// TranscribeOption 转录可选参数
+//
+// 不支持的参数会被引擎忽略，例如 Paraformer 不区分语言与任务
+type TranscribeOption struct {
+	Language string // 被转录的语言，例如："zh", "en", "ja"，Whisper 支持 "auto" 自动检测
+	Task     string // 任务类型，例如："transcribe", "translate"
+
+	// SplitChannels 对多声道音频的每个声道分别识别，结果按声道标记并合并为按时间排序的对话
+	//
+	// 仅对 TranscribeBytes 与 TranscribeFile 生效，适用于坐席与客户分别录制在左右声道的通话录音
+	SplitChannels bool
+	ChannelLabels []string // 各声道的说话人标签，例如：[]string{"坐席", "客户"}，默认为 "声道1"、"声道2"...
+
+	// WithTimestamps 生成时间戳，结果的 Segments 给出带时间范围与 Token 的片段 (仅 Whisper)
+	WithTimestamps bool
+}
+
+// Result 识别结果
+type Result struct {
+	Text     string // 识别文本
+	Language string // 识别所使用的语言，引擎无法确定时为空
+
+	Words    []Word          // 字词级时间戳，仅在模型支持时填充，例如带 us_cif_peak 输出的 Paraformer 模型
+	Segments []Segment       // 长音频识别时各语音段的结果，按时间排序
+	Channels []ChannelResult // 按声道分别识别时各声道的结果
+	Dialogue []Utterance     // 按声道分别识别时所有声道的语句，按开始时间排序
+}
+
+// Word 带时间戳的字或词
+type Word struct {
+	Text  string        // 文本，不含标点
+	Start time.Duration // 起始时间
+	End   time.Duration // 结束时间
+}
+
+// Segment 带时间范围的一段识别文本
+type Segment struct {
+	Start time.Duration // 起始时间
+	End   time.Duration // 结束时间
+	Text  string        // 识别文本
+
+	Tokens []int // 片段的 Token ID (包含首尾的时间戳 Token)，仅 Whisper 生成时间戳时填充
+}
+
+// String 返回识别文本
+func (r *Result) String() string {
+	if r == nil {
+		return ""
+	}
+	return r.Text
+}
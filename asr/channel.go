@@ -0,0 +1,178 @@
+package asr
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/getcharzp/go-speech/audio"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Utterance 某个声道中一段连续语音的识别结果
+type Utterance struct {
+	Channel int           // 声道序号，从 0 开始
+	Speaker string        // 声道标签，取自 TranscribeOption.ChannelLabels
+	Start   time.Duration // 语音段在音频中的起始时间
+	End     time.Duration // 语音段在音频中的结束时间
+	Text    string        // 识别文本
+}
+
+// ChannelResult 单个声道的识别结果
+type ChannelResult struct {
+	Channel    int         // 声道序号，从 0 开始
+	Speaker    string      // 声道标签
+	Text       string      // 该声道的完整文本
+	Utterances []Utterance // 该声道的语句，按开始时间排序
+}
+
+// 语音段检测参数
+const (
+	vadFrame      = SampleRate * 30 / 1000 // 30ms 分析帧
+	vadMinSilence = 500 * time.Millisecond // 短于该时长的停顿不切分语句
+	vadMinSpeech  = 150 * time.Millisecond // 短于该时长的语音视为噪声
+	vadPadding    = 100 * time.Millisecond // 语音段两侧保留的静音
+	vadFloorDB    = -55                    // 低于该能量 (dBFS) 的帧总是视为静音
+)
+
+// span 样本区间 [start, end)
+type span struct {
+	start, end int
+}
+
+// speechSpans 基于短时能量检测 16KHz 单声道音频中的语音段，超过 maxLen 的语音段在能量最低处切分
+//
+// 阈值随录音底噪自适应：取帧能量的 10% 分位作为底噪，高于底噪 12dB 的帧视为语音
+func speechSpans(samples []float32, maxLen int) []span {
+	n := (len(samples) + vadFrame - 1) / vadFrame
+	if n == 0 {
+		return nil
+	}
+	db := make([]float64, n)
+	for i := range db {
+		frame := samples[i*vadFrame : min((i+1)*vadFrame, len(samples))]
+		var energy float64
+		for _, v := range frame {
+			energy += float64(v) * float64(v)
+		}
+		db[i] = 10 * math.Log10(energy/float64(len(frame))+1e-10)
+	}
+	sorted := slices.Clone(db)
+	slices.Sort(sorted)
+	floor, peak := sorted[n/10], sorted[n-1]
+	// 整段都是语音时底噪被高估，阈值不超过峰值以下 25dB
+	threshold := max(min(floor+12, peak-25), vadFloorDB)
+
+	frames := func(d time.Duration) int { return int(d.Seconds() * SampleRate / vadFrame) }
+	minSilence, minSpeech, padding := frames(vadMinSilence), frames(vadMinSpeech), int(vadPadding.Seconds()*SampleRate)
+
+	// 合并间隔较短的语音帧
+	var runs []span
+	for i := 0; i < n; i++ {
+		if db[i] < threshold {
+			continue
+		}
+		if len(runs) > 0 && i-runs[len(runs)-1].end < minSilence {
+			runs[len(runs)-1].end = i + 1
+		} else {
+			runs = append(runs, span{i, i + 1})
+		}
+	}
+
+	var spans []span
+	for _, r := range runs {
+		if r.end-r.start < minSpeech {
+			continue
+		}
+		start := max(r.start*vadFrame-padding, 0)
+		end := min(r.end*vadFrame+padding, len(samples))
+		spans = append(spans, splitSpan(samples, span{start, end}, maxLen)...)
+	}
+	return spans
+}
+
+// splitSpan 将超过 maxLen 的区间在 [maxLen/2, maxLen] 中能量最低处切分
+func splitSpan(samples []float32, s span, maxLen int) []span {
+	var spans []span
+	for s.end-s.start > maxLen {
+		cut := s.start + quietest(samples[s.start:], maxLen/2, maxLen)
+		spans = append(spans, span{s.start, cut})
+		s.start = cut
+	}
+	return append(spans, s)
+}
+
+// TranscribeChannels 对每个声道分别检测语音段并识别，合并为按时间排序的对话，供各引擎实现按声道识别
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	buf: 任意采样率的多声道音频，各声道转换为 16KHz 后识别
+//	opt: 转录参数，使用其中的 ChannelLabels 标记声道
+//	maxDuration: 单次识别的最大时长，更长的语音段在能量最低处切分
+//	transcribe: 识别 16KHz 单声道样本
+//
+// 返回结果的 Text 为逐行的 "标签: 文本"，音频为空时返回 ErrEmptyStream
+func TranscribeChannels(ctx context.Context, buf *audio.Buffer, opt TranscribeOption, maxDuration time.Duration, transcribe func(ctx context.Context, samples []float32) (*Result, error)) (*Result, error) {
+	if buf.Frames() == 0 {
+		return nil, ErrEmptyStream
+	}
+	maxLen, err := maxSamples(maxDuration)
+	if err != nil {
+		return nil, err
+	}
+	result := new(Result)
+	for ch := 0; ch < buf.Channels; ch++ {
+		speaker := fmt.Sprintf("声道%d", ch+1)
+		if ch < len(opt.ChannelLabels) && opt.ChannelLabels[ch] != "" {
+			speaker = opt.ChannelLabels[ch]
+		}
+		samples := audio.Resample(buf.Channel(ch), buf.SampleRate, SampleRate)
+
+		cr := ChannelResult{Channel: ch, Speaker: speaker}
+		var texts []string
+		for _, s := range speechSpans(samples, maxLen) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			start := time.Duration(s.start) * time.Second / SampleRate
+			r, err := transcribe(ctx, samples[s.start:s.end])
+			if err != nil {
+				return nil, fmt.Errorf("识别%s %v 处的语音失败: %w", speaker, start, err)
+			}
+			if r == nil {
+				return nil, fmt.Errorf("识别%s %v 处的语音没有返回结果", speaker, start)
+			}
+			if result.Language == "" {
+				result.Language = r.Language
+			}
+			text := strings.TrimSpace(r.Text)
+			if text == "" {
+				continue
+			}
+			texts = append(texts, text)
+			cr.Utterances = append(cr.Utterances, Utterance{
+				Channel: ch,
+				Speaker: speaker,
+				Start:   start,
+				End:     time.Duration(s.end) * time.Second / SampleRate,
+				Text:    text,
+			})
+		}
+		cr.Text = JoinText(texts)
+		result.Channels = append(result.Channels, cr)
+		result.Dialogue = append(result.Dialogue, cr.Utterances...)
+	}
+
+	slices.SortStableFunc(result.Dialogue, func(a, b Utterance) int {
+		return cmp.Compare(a.Start, b.Start)
+	})
+	lines := make([]string, len(result.Dialogue))
+	for i, u := range result.Dialogue {
+		lines[i] = u.Speaker + ": " + u.Text
+	}
+	result.Text = strings.Join(lines, "\n")
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"github.com/getcharzp/go-speech/audio"
+	"math"
+	"testing"
+	"time"
+)
+
+// tone 在 [from, to) 秒内写入 440Hz 正弦波
+func tone(data []float32, rate, channels, ch int, from, to float64) {
+	for i := int(from * float64(rate)); i < int(to*float64(rate)); i++ {
+		data[i*channels+ch] = float32(0.5 * math.Sin(2*math.Pi*440*float64(i)/float64(rate)))
+	}
+}
+
+func TestTranscribeChannels(t *testing.T) {
+	const rate = 8000
+	buf := &audio.Buffer{SampleRate: rate, Channels: 2, Data: make([]float32, 2*rate*6)}
+	tone(buf.Data, rate, 2, 0, 0.5, 1.5)
+	tone(buf.Data, rate, 2, 1, 2, 3)
+	tone(buf.Data, rate, 2, 0, 4, 5)
+	// 左声道上的微弱串音不应被识别
+	for i := 2 * rate; i < 3*rate; i++ {
+		buf.Data[2*i] = buf.Data[2*i+1] * 0.001
+	}
+
+	result, err := TranscribeChannels(context.Background(), buf, TranscribeOption{ChannelLabels: []string{"坐席"}}, 20*time.Second,
+		func(_ context.Context, samples []float32) (*Result, error) {
+			return &Result{Text: fmt.Sprintf("%.1fs", float64(len(samples))/SampleRate)}, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Channels) != 2 || len(result.Channels[0].Utterances) != 2 || len(result.Channels[1].Utterances) != 1 {
+		t.Fatalf("channels: %+v", result.Channels)
+	}
+	want := []struct {
+		speaker string
+		start   time.Duration
+	}{{"坐席", 500 * time.Millisecond}, {"声道2", 2 * time.Second}, {"坐席", 4 * time.Second}}
+	for i, u := range result.Dialogue {
+		if u.Speaker != want[i].speaker || (u.Start-want[i].start).Abs() > 150*time.Millisecond || u.End-u.Start < time.Second {
+			t.Fatalf("utterance %d: %+v", i, u)
+		}
+	}
+	if result.Text != "坐席: 1.2s\n声道2: 1.2s\n坐席: 1.2s" {
+		t.Fatalf("text: %q", result.Text)
+	}
+
+	if _, err := TranscribeChannels(context.Background(), &audio.Buffer{SampleRate: rate, Channels: 2}, TranscribeOption{}, time.Second, nil); err != ErrEmptyStream {
+		t.Fatalf("empty: %v", err)
+	}
+	if _, err := TranscribeChannels(context.Background(), buf, TranscribeOption{}, 0, nil); err == nil {
+		t.Fatal("expected maxDuration error")
+	}
+	if _, err := TranscribeChannels(context.Background(), buf, TranscribeOption{}, 20*time.Second,
+		func(context.Context, []float32) (*Result, error) { return nil, nil }); err == nil {
+		t.Fatal("expected nil result error")
+	}
+}
+
+func TestSpeechSpans(t *testing.T) {
+	samples := make([]float32, SampleRate*12)
+	tone(samples, SampleRate, 1, 0, 1, 11)
+	spans := speechSpans(samples, 4*SampleRate)
+	if len(spans) < 3 || spans[0].start > SampleRate || spans[len(spans)-1].end < 11*SampleRate {
+		t.Fatalf("spans: %v", spans)
+	}
+	for i, s := range spans {
+		if s.end-s.start > 4*SampleRate || i > 0 && s.start != spans[i-1].end {
+			t.Fatalf("spans: %v", spans)
+		}
+	}
+	if spans := speechSpans(make([]float32, SampleRate), SampleRate); len(spans) != 0 {
+		t.Fatalf("silence: %v", spans)
+	}
+}
@@ -0,0 +1,199 @@
+package asr
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Span 语音段在音频中的时间范围
+type Span struct {
+	Start time.Duration // 起始时间
+	End   time.Duration // 结束时间
+}
+
+// Splitter 将长音频切分为语音段，*vad.Engine 实现了该接口
+type Splitter interface {
+	// Split 返回 16KHz 单声道音频中的语音段
+	Split(samples []float32) ([]Span, error)
+}
+
+// LongOption 长音频识别参数
+type LongOption struct {
+	TranscribeOption
+
+	// Splitter 将音频切分为语音段，例如 *vad.Engine，默认按短时能量检测静音切分
+	//
+	// 超过引擎单次识别时长的语音段会在能量最低处继续切分
+	Splitter Splitter
+}
+
+// TranscribeLong 切分长音频并识别各语音段，合并为按时间排序的结果，供各引擎实现 TranscribeLong
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	samples: 采样率为 16KHz 的单声道音频数据
+//	splitter: 切分语音段，nil 时按静音切分
+//	maxDuration: 单次识别的最大时长
+//	workers: 并行识别的语音段数量，大于 1 时 transcribe 会被并发调用
+//	transcribe: 识别单个语音段
+//	punctuate: (可选) 对各语音段的文本统一加标点，返回与输入一一对应的文本
+//
+// 音频为空时返回 ErrEmptyStream，任一语音段识别失败或没有返回结果时取消其余语音段并返回错误
+func TranscribeLong(ctx context.Context, samples []float32, splitter Splitter, maxDuration time.Duration, workers int,
+	transcribe func(ctx context.Context, samples []float32) (*Result, error),
+	punctuate func(ctx context.Context, texts []string) ([]string, error)) (*Result, error) {
+	if len(samples) == 0 {
+		return nil, ErrEmptyStream
+	}
+	maxLen, err := maxSamples(maxDuration)
+	if err != nil {
+		return nil, err
+	}
+	spans, err := splitLong(samples, splitter, maxLen)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := transcribeSpans(ctx, samples, spans, workers, transcribe)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(Result)
+	for i, r := range results {
+		offset := time.Duration(spans[i].start) * time.Second / SampleRate
+		if result.Language == "" {
+			result.Language = r.Language
+		}
+		for _, w := range r.Words {
+			result.Words = append(result.Words, Word{Text: w.Text, Start: offset + w.Start, End: offset + w.End})
+		}
+		if len(r.Segments) > 0 {
+			for _, s := range r.Segments {
+				s.Start, s.End, s.Text = offset+s.Start, offset+s.End, strings.TrimSpace(s.Text)
+				result.Segments = append(result.Segments, s)
+			}
+			continue
+		}
+		result.Segments = append(result.Segments, Segment{
+			Start: offset,
+			End:   time.Duration(spans[i].end) * time.Second / SampleRate,
+			Text:  strings.TrimSpace(r.Text),
+		})
+	}
+	result.Segments = slices.DeleteFunc(result.Segments, func(s Segment) bool { return s.Text == "" })
+
+	texts := make([]string, len(result.Segments))
+	for i, s := range result.Segments {
+		texts[i] = s.Text
+	}
+	if punctuate != nil && len(texts) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		punctuated, err := punctuate(ctx, texts)
+		if err != nil {
+			return nil, fmt.Errorf("标点预测失败: %w", err)
+		}
+		if len(punctuated) != len(texts) {
+			return nil, fmt.Errorf("标点预测返回 %d 段文本，期望 %d 段", len(punctuated), len(texts))
+		}
+		texts = punctuated
+		for i := range result.Segments {
+			result.Segments[i].Text = texts[i]
+		}
+	}
+	result.Text = JoinText(texts)
+	return result, nil
+}
+
+// maxSamples 将单次识别的最大时长转换为样本数，不足一个样本时返回错误，避免切分时无法前进
+func maxSamples(maxDuration time.Duration) (int, error) {
+	n := int(maxDuration.Seconds() * SampleRate)
+	if n <= 0 {
+		return 0, fmt.Errorf("单次识别的最大时长必须大于 0: %v", maxDuration)
+	}
+	return n, nil
+}
+
+// splitLong 切分语音段，并将超过 maxLen 的语音段在能量最低处切分
+func splitLong(samples []float32, splitter Splitter, maxLen int) ([]span, error) {
+	if splitter == nil {
+		return speechSpans(samples, maxLen), nil
+	}
+	found, err := splitter.Split(samples)
+	if err != nil {
+		return nil, fmt.Errorf("切分语音段失败: %w", err)
+	}
+	slices.SortFunc(found, func(a, b Span) int { return cmp.Compare(a.Start, b.Start) })
+
+	toSample := func(d time.Duration) int {
+		return min(max(int(d.Seconds()*SampleRate), 0), len(samples))
+	}
+	var spans []span
+	for _, s := range found {
+		start, end := toSample(s.Start), toSample(s.End)
+		if len(spans) > 0 {
+			start = max(start, spans[len(spans)-1].end)
+		}
+		if end <= start {
+			continue
+		}
+		spans = append(spans, splitSpan(samples, span{start, end}, maxLen)...)
+	}
+	return spans, nil
+}
+
+// transcribeSpans 使用 workers 个协程识别各语音段，结果与 spans 一一对应
+func transcribeSpans(ctx context.Context, samples []float32, spans []span, workers int, transcribe func(ctx context.Context, samples []float32) (*Result, error)) ([]*Result, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([]*Result, len(spans))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(max(workers, 1), len(spans)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() != nil {
+					continue
+				}
+				s := spans[i]
+				r, err := transcribe(ctx, samples[s.start:s.end])
+				if err != nil {
+					cancel(fmt.Errorf("识别 %v 处的语音段失败: %w", time.Duration(s.start)*time.Second/SampleRate, err))
+					continue
+				}
+				if r == nil {
+					cancel(fmt.Errorf("识别 %v 处的语音段没有返回结果", time.Duration(s.start)*time.Second/SampleRate))
+					continue
+				}
+				results[i] = r
+			}
+		}()
+	}
+
+feed:
+	for i := range spans {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, context.Cause(ctx)
+	}
+	return results, nil
+}
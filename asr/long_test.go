@@ -0,0 +1,100 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// spanSplitter 返回固定的语音段
+type spanSplitter []Span
+
+func (s spanSplitter) Split([]float32) ([]Span, error) {
+	return s, nil
+}
+
+func TestTranscribeLong(t *testing.T) {
+	samples := make([]float32, 60*SampleRate)
+	for i := range samples {
+		samples[i] = float32(i%100) / 100
+	}
+	splitter := spanSplitter{
+		{Start: 40 * time.Second, End: 45 * time.Second},
+		{Start: 2 * time.Second, End: 27 * time.Second}, // 超过 maxDuration，需要继续切分
+		{Start: 30 * time.Second, End: 31 * time.Second},
+	}
+
+	var running, peak atomic.Int32
+	transcribe := func(ctx context.Context, samples []float32) (*Result, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		time.Sleep(10 * time.Millisecond)
+		if len(samples) == SampleRate {
+			return &Result{}, nil // 没有识别出文本的语音段被丢弃
+		}
+		d := time.Duration(len(samples)) * time.Second / SampleRate
+		return &Result{Text: fmt.Sprintf("%.0fs", d.Seconds()), Words: []Word{{Text: "w", Start: time.Second, End: 2 * time.Second}}}, nil
+	}
+	punctuate := func(_ context.Context, texts []string) ([]string, error) {
+		out := make([]string, len(texts))
+		for i, text := range texts {
+			out[i] = text + "。"
+		}
+		return out, nil
+	}
+
+	result, err := TranscribeLong(context.Background(), samples, splitter, 20*time.Second, 3, transcribe, punctuate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak.Load() < 2 {
+		t.Fatalf("语音段未并行识别: %d", peak.Load())
+	}
+	if len(result.Segments) != 3 {
+		t.Fatalf("segments: %+v", result.Segments)
+	}
+	if first, second := result.Segments[0], result.Segments[1]; first.Start != 2*time.Second || first.End != second.Start || second.End != 27*time.Second {
+		t.Fatalf("segments: %+v", result.Segments)
+	}
+	if last := result.Segments[2]; last.Start != 40*time.Second || last.End != 45*time.Second || last.Text != "5s。" {
+		t.Fatalf("segments: %+v", result.Segments)
+	}
+	if !strings.HasSuffix(result.Text, "5s。") || strings.Count(result.Text, "。") != 3 {
+		t.Fatalf("text: %q", result.Text)
+	}
+	if len(result.Words) != 3 || result.Words[0].Start != 3*time.Second || result.Words[2].Start != 41*time.Second {
+		t.Fatalf("words: %+v", result.Words)
+	}
+
+	// 任一语音段失败时返回错误
+	errFail := errors.New("fail")
+	_, err = TranscribeLong(context.Background(), samples, splitter, 20*time.Second, 2, func(ctx context.Context, samples []float32) (*Result, error) {
+		return nil, errFail
+	}, nil)
+	if !errors.Is(err, errFail) {
+		t.Fatalf("err: %v", err)
+	}
+
+	if _, err := TranscribeLong(context.Background(), nil, nil, 20*time.Second, 1, transcribe, nil); !errors.Is(err, ErrEmptyStream) {
+		t.Fatalf("err: %v", err)
+	}
+
+	// 没有返回结果的语音段视为失败
+	if _, err := TranscribeLong(context.Background(), samples, splitter, 20*time.Second, 2, func(ctx context.Context, samples []float32) (*Result, error) {
+		return nil, nil
+	}, nil); err == nil {
+		t.Fatal("expected nil result error")
+	}
+
+	for _, d := range []time.Duration{0, -time.Second, time.Nanosecond} {
+		if _, err := TranscribeLong(context.Background(), samples, nil, d, 1, transcribe, nil); err == nil {
+			t.Fatalf("maxDuration %v: expected error", d)
+		}
+	}
+}
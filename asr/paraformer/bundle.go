@@ -0,0 +1,47 @@
+package paraformer
+
+import (
+	"crypto/ed25519"
+	"github.com/getcharzp/go-speech"
+)
+
+// LoadBundle 从模型包目录加载 Paraformer 引擎
+//
+// 模型包需包含 manifest.json，文件角色: model, tokens, cmvn, (可选) punctuation_model, punctuation_tokens, hotword_model
+//
+// # Params:
+//
+//	dir: 模型包目录
+//	publicKey: (可选) Ed25519 公钥，非空时校验清单签名
+func LoadBundle(dir string, publicKey ed25519.PublicKey) (*Engine, error) {
+	cfg, err := ConfigFromBundle(dir, DefaultConfig(), publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(cfg)
+}
+
+// ConfigFromBundle 校验模型包并将其中的文件路径填入配置
+//
+// # Params:
+//
+//	dir: 模型包目录
+//	cfg: 基础配置，ONNX 相关参数保持不变
+//	publicKey: (可选) Ed25519 公钥，非空时校验清单签名
+func ConfigFromBundle(dir string, cfg Config, publicKey ed25519.PublicKey) (Config, error) {
+	m, err := speech.OpenBundle(dir, "paraformer", publicKey)
+	if err != nil {
+		return cfg, err
+	}
+	if err := m.Require("model", "tokens", "cmvn"); err != nil {
+		return cfg, err
+	}
+
+	cfg.ModelPath = m.Path("model")
+	cfg.TokensPath = m.Path("tokens")
+	cfg.CMVNPath = m.Path("cmvn")
+	cfg.PunctuationModelPath = m.Path("punctuation_model")
+	cfg.PunctuationTokensPath = m.Path("punctuation_tokens")
+	cfg.HotwordModelPath = m.Path("hotword_model")
+	return cfg, nil
+}
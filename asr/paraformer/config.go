@@ -1,30 +1,52 @@
 package paraformer
 
-import "github.com/getcharzp/go-speech"
+import (
+	"fmt"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"io"
+	"log/slog"
+	"time"
+)
 
 const (
+	// engineName 引擎名称，用于注册、日志与错误
+	engineName = "paraformer"
 	// sampleRate 采样率
 	sampleRate = 16000
-	// channels 声道数
-	channels = 1
-	// bitsPerSample 采样位数
-	bitsPerSample = 16
+	// chunkMinDuration, chunkMaxDuration TranscribeReader 的片段时长范围，在该范围内能量最低的位置切分
+	chunkMinDuration = 10 * time.Second
+	chunkMaxDuration = 20 * time.Second
+
+	// onlineEngineName 流式引擎名称
+	onlineEngineName = "paraformer-online"
 )
 
 // Config 定义 Paraformer 模型的配置参数
 type Config struct {
 	// 必填参数
-	OnnxRuntimeLibPath string // onnxruntime.dll (或 .so, .dylib) 的路径
-	ModelPath          string // ONNX 模型路径
-	TokensPath         string // tokens.txt 路径
-	CMVNPath           string // am.mvn 文件路径
+	OnnxRuntimeLibPath string `json:"onnx_runtime_lib_path"` // onnxruntime.dll (或 .so, .dylib) 的路径
+	ModelPath          string `json:"model_path"`            // ONNX 模型路径
+	TokensPath         string `json:"tokens_path"`           // tokens.txt 路径
+	CMVNPath           string `json:"cmvn_path"`             // am.mvn 文件路径
 
 	// 可选参数
-	PunctuationModelPath  string // 标点模型路径
-	PunctuationTokensPath string // 标点 tokens.json 路径
-	UseCuda               bool   // (可选) 是否启用 CUDA
-	NumThreads            int    // (可选) ONNX 线程数, 默认由CPU核心数决定
-	EnableCpuMemArena     bool   // (可选) 是否启用内存池
+	PunctuationModelPath   string          `json:"punctuation_model_path"`   // 标点模型路径
+	PunctuationTokensPath  string          `json:"punctuation_tokens_path"`  // 标点 tokens.json 路径
+	HotwordModelPath       string          `json:"hotword_model_path"`       // (可选) SeACo-Paraformer 热词嵌入模型 model_eb.onnx 路径，模型带 bias_embed 输入时必填
+	Hotwords               []Hotword       `json:"hotwords"`                 // (可选) 初始热词，运行时可通过 Engine.SetHotwords 更新
+	UseCuda                bool            `json:"use_cuda"`                 // (可选) 是否启用 CUDA
+	NumThreads             int             `json:"num_threads"`              // (可选) ONNX 线程数, 默认由CPU核心数决定
+	EnableCpuMemArena      bool            `json:"enable_cpu_mem_arena"`     // (可选) 是否启用内存池
+	GraphOptimizationLevel string          `json:"graph_optimization_level"` // (可选) 图优化级别: disable, basic, extended, all
+	ExecutionMode          string          `json:"execution_mode"`           // (可选) 执行模式: sequential, parallel
+	InterOpNumThreads      int             `json:"inter_op_num_threads"`     // (可选) 算子间并行线程数，仅 parallel 模式生效
+	DisableMemPattern      bool            `json:"disable_mem_pattern"`      // (可选) 禁用内存模式优化
+	OptimizedModelDir      string          `json:"optimized_model_dir"`      // (可选) 优化后模型的缓存目录，用于缩短冷启动时间
+	Runtime                *speech.Runtime `json:"-"`                        // (可选) 共享的 ONNX 运行时，设置后忽略 OnnxRuntimeLibPath
+	Logger                 *slog.Logger    `json:"-"`                        // (可选) 日志记录器，默认使用 slog.Default()
+	Observer               speech.Observer `json:"-"`                        // (可选) 推理观测者，接收各阶段耗时、音频时长、Token 数与实时率
+	Splitter               asr.Splitter    `json:"-"`                        // (可选) TranscribeReader 的语音段切分器，例如 *vad.Engine，为空时在能量最低处切分
 }
 
 // DefaultConfig 返回一套默认的配置 (基于常见的目录结构)
@@ -36,3 +58,83 @@ func DefaultConfig() Config {
 		CMVNPath:           "./paraformer_weights/am.mvn",
 	}
 }
+
+// OnlineConfig 定义流式 Paraformer 模型 (encoder/decoder) 的配置参数
+type OnlineConfig struct {
+	// 必填参数
+	OnnxRuntimeLibPath string `json:"onnx_runtime_lib_path"` // onnxruntime.dll (或 .so, .dylib) 的路径
+	EncoderModelPath   string `json:"encoder_model_path"`    // 流式编码器 ONNX 模型路径
+	DecoderModelPath   string `json:"decoder_model_path"`    // 流式解码器 ONNX 模型路径
+	TokensPath         string `json:"tokens_path"`           // tokens.txt 路径
+	CMVNPath           string `json:"cmvn_path"`             // am.mvn 文件路径
+
+	// 模型结构参数，与导出模型的 config.yaml 一致
+	ChunkSize         []int   `json:"chunk_size"`          // (可选) 分块大小 [历史, 当前, 前瞻]，单位为 60ms 的 LFR 帧，默认 [5, 10, 5]
+	EncoderOutputSize int     `json:"encoder_output_size"` // (可选) 编码器输出维度，默认 512
+	DecoderKernelSize int     `json:"decoder_kernel_size"` // (可选) 解码器 FSMN 卷积核大小，默认 11
+	CifThreshold      float32 `json:"cif_threshold"`       // (可选) CIF 触发阈值，默认 1.0
+	TailThreshold     float32 `json:"tail_threshold"`      // (可选) 结束时补充的 CIF 权重，默认 0.45
+
+	// 可选参数
+	UseCuda                bool            `json:"use_cuda"`                 // (可选) 是否启用 CUDA
+	NumThreads             int             `json:"num_threads"`              // (可选) ONNX 线程数, 默认由CPU核心数决定
+	EnableCpuMemArena      bool            `json:"enable_cpu_mem_arena"`     // (可选) 是否启用内存池
+	GraphOptimizationLevel string          `json:"graph_optimization_level"` // (可选) 图优化级别: disable, basic, extended, all
+	ExecutionMode          string          `json:"execution_mode"`           // (可选) 执行模式: sequential, parallel
+	InterOpNumThreads      int             `json:"inter_op_num_threads"`     // (可选) 算子间并行线程数，仅 parallel 模式生效
+	DisableMemPattern      bool            `json:"disable_mem_pattern"`      // (可选) 禁用内存模式优化
+	OptimizedModelDir      string          `json:"optimized_model_dir"`      // (可选) 优化后模型的缓存目录，用于缩短冷启动时间
+	Runtime                *speech.Runtime `json:"-"`                        // (可选) 共享的 ONNX 运行时，设置后忽略 OnnxRuntimeLibPath
+	Logger                 *slog.Logger    `json:"-"`                        // (可选) 日志记录器，默认使用 slog.Default()
+	Observer               speech.Observer `json:"-"`                        // (可选) 推理观测者，每次 AcceptWaveform 与 FinalResult 调用上报一次
+}
+
+// DefaultOnlineConfig 返回一套默认的流式模型配置 (基于常见的目录结构)
+func DefaultOnlineConfig() OnlineConfig {
+	return OnlineConfig{
+		OnnxRuntimeLibPath: speech.DefaultLibraryPath(),
+		EncoderModelPath:   "./paraformer_online_weights/encoder.int8.onnx",
+		DecoderModelPath:   "./paraformer_online_weights/decoder.int8.onnx",
+		TokensPath:         "./paraformer_online_weights/tokens.txt",
+		CMVNPath:           "./paraformer_online_weights/am.mvn",
+		ChunkSize:          []int{5, 10, 5},
+		EncoderOutputSize:  512,
+		DecoderKernelSize:  11,
+		CifThreshold:       1.0,
+		TailThreshold:      0.45,
+	}
+}
+
+func init() {
+	speech.Register(engineName, func(spec speech.EngineSpec) (io.Closer, error) {
+		cfg := DefaultConfig()
+		if spec.Bundle != "" {
+			var err error
+			if cfg, err = ConfigFromBundle(spec.Bundle, cfg, spec.PublicKey); err != nil {
+				return nil, err
+			}
+		}
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		engine, err := NewEngine(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	})
+	speech.Register(onlineEngineName, func(spec speech.EngineSpec) (io.Closer, error) {
+		if spec.Bundle != "" {
+			return nil, fmt.Errorf("流式 Paraformer 暂不支持模型包")
+		}
+		cfg := DefaultOnlineConfig()
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		engine, err := NewOnlineEngine(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	})
+}
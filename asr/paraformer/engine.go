@@ -1,32 +1,62 @@
 package paraformer
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"github.com/getcharzp/go-speech/audio"
+	"github.com/getcharzp/go-speech/internal/fbank"
 	ort "github.com/getcharzp/onnxruntime_purego"
 	"github.com/up-zero/gotool/convertutil"
 	"github.com/up-zero/gotool/validator"
+	"io"
+	"log/slog"
 	"math"
 	"os"
+	"slices"
 	"strings"
+	"sync/atomic"
 )
 
 // Engine 封装了 Paraformer ASR 的 ONNX 运行时和相关资源
+//
+// Engine 不是并发安全的，并发推理请使用 speech.Pool 持有多个实例
 type Engine struct {
+	onnx     *speech.OnnxConfig
 	session  *ort.Session
 	tokenMap map[int]string
 	negMean  []float32 // CMVN 均值
 	invStd   []float32 // CMVN 方差倒数
 
+	timestamp bool // 模型是否输出 us_alphas 与 us_cif_peak，支持字级时间戳
+
+	// 热词相关 (SeACo-Paraformer)
+	hotwordSession *ort.Session                // 热词嵌入模型，模型不支持热词时为 nil
+	bias           atomic.Pointer[hotwordBias] // 当前生效的热词偏置
+	vocab          map[string]int              // 文本 -> ID
+
 	// 标点模型相关
 	punctuationSession  *ort.Session
 	punctuationTokenMap map[string]int // 文本 -> ID
 	punctuationList     []string       // 标点符号
+
+	splitter asr.Splitter // TranscribeReader 使用的语音段切分器
+
+	logger   *slog.Logger
+	observer speech.Observer
 }
 
+var _ asr.Recognizer = (*Engine)(nil)
+
 // NewEngine 初始化 Paraformer ASR 引擎
-func NewEngine(cfg Config) (*Engine, error) {
+//
+// 失败时返回的错误满足 errors.Is(err, speech.ErrModelLoad)
+func NewEngine(cfg Config) (_ *Engine, err error) {
+	defer func() { err = speech.WrapError(speech.ErrModelLoad, engineName, err) }()
+
 	oc := new(speech.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 
@@ -34,29 +64,69 @@ func NewEngine(cfg Config) (*Engine, error) {
 	if err := oc.New(); err != nil {
 		return nil, err
 	}
+	engine := &Engine{onnx: oc, logger: speech.EngineLogger(cfg.Logger, engineName), observer: cfg.Observer, splitter: cfg.Splitter}
 
 	// 加载资源 (Tokens 和 CMVN)
 	tokenMap, err := loadTokens(cfg.TokensPath)
 	if err != nil {
+		engine.Destroy()
 		return nil, fmt.Errorf("加载词表失败: %w", err)
 	}
-	negMean, invStd, err := loadCMVN(cfg.CMVNPath)
+	negMean, invStd, err := fbank.LoadCMVN(cfg.CMVNPath)
 	if err != nil {
 		// 某些模型可能不强制需要 CMVN，这里根据需求决定是报错还是警告
+		engine.Destroy()
 		return nil, fmt.Errorf("加载 CMVN 失败: %w", err)
 	}
+	engine.tokenMap = tokenMap
+	engine.negMean = negMean
+	engine.invStd = invStd
 
 	// 创建 ONNX 会话
-	session, err := oc.OnnxEngine.NewSession(cfg.ModelPath, oc.SessionOptions)
+	session, err := oc.NewSession(cfg.ModelPath)
 	if err != nil {
+		engine.Destroy()
 		return nil, fmt.Errorf("创建 ONNX 会话失败: %w", err)
 	}
-
-	engine := &Engine{
-		session:  session,
-		tokenMap: tokenMap,
-		negMean:  negMean,
-		invStd:   invStd,
+	engine.session = session
+	inputs := []string{"speech", "speech_lengths"}
+	seaco := slices.Contains(session.InputNames, "bias_embed")
+	if seaco {
+		inputs = append(inputs, "bias_embed")
+	}
+	if err := speech.CheckSession(session, inputs, []string{"logits"}); err != nil {
+		engine.Destroy()
+		return nil, err
+	}
+	engine.timestamp = slices.Contains(session.OutputNames, "us_alphas") && slices.Contains(session.OutputNames, "us_cif_peak")
+	engine.logger.Debug("模型输出", "outputs", session.OutputNames, "timestamp", engine.timestamp)
+
+	// 加载热词嵌入模型
+	if seaco {
+		if cfg.HotwordModelPath == "" {
+			engine.Destroy()
+			return nil, fmt.Errorf("模型带有 bias_embed 输入，需要设置 HotwordModelPath")
+		}
+		hSession, err := oc.NewSession(cfg.HotwordModelPath)
+		if err != nil {
+			engine.Destroy()
+			return nil, fmt.Errorf("创建热词嵌入模型会话失败: %w", err)
+		}
+		engine.hotwordSession = hSession
+		if len(hSession.InputNames) != 1 || len(hSession.OutputNames) == 0 {
+			engine.Destroy()
+			return nil, fmt.Errorf("热词嵌入模型的输入输出不匹配: %v -> %v", hSession.InputNames, hSession.OutputNames)
+		}
+		engine.vocab = make(map[string]int, len(tokenMap))
+		for id, token := range tokenMap {
+			engine.vocab[token] = id
+		}
+		if err := engine.SetHotwords(cfg.Hotwords); err != nil {
+			engine.Destroy()
+			return nil, err
+		}
+	} else if len(cfg.Hotwords) > 0 {
+		engine.logger.Warn("模型不支持热词，已忽略 Hotwords，请使用 SeACo-Paraformer")
 	}
 
 	// 加载标点模型
@@ -64,6 +134,7 @@ func NewEngine(cfg Config) (*Engine, error) {
 		// 加载标点词表 tokens.json
 		pTokenMap, err := loadPunctuationTokens(cfg.PunctuationTokensPath)
 		if err != nil {
+			engine.Destroy()
 			return nil, fmt.Errorf("加载标点词表失败: %w", err)
 		}
 		engine.punctuationTokenMap = pTokenMap
@@ -72,11 +143,16 @@ func NewEngine(cfg Config) (*Engine, error) {
 		engine.punctuationList = []string{"", "", "，", "。", "？", "、"}
 
 		// 创建标点模型会话
-		pSession, err := oc.OnnxEngine.NewSession(cfg.PunctuationModelPath, oc.SessionOptions)
+		pSession, err := oc.NewSession(cfg.PunctuationModelPath)
 		if err != nil {
+			engine.Destroy()
 			return nil, err
 		}
 		engine.punctuationSession = pSession
+		if err := speech.CheckSession(pSession, []string{"inputs", "text_lengths"}, []string{"logits"}); err != nil {
+			engine.Destroy()
+			return nil, fmt.Errorf("标点模型: %w", err)
+		}
 	}
 
 	return engine, nil
@@ -90,32 +166,109 @@ func (e *Engine) Destroy() {
 	if e.punctuationSession != nil {
 		e.punctuationSession.Destroy()
 	}
+	if e.hotwordSession != nil {
+		e.hotwordSession.Destroy()
+	}
+	if e.onnx != nil {
+		e.onnx.Destroy()
+	}
+}
+
+// Close 释放相关资源，实现 asr.Recognizer 接口
+func (e *Engine) Close() error {
+	e.Destroy()
+	return nil
 }
 
-// TranscribeFile 读取 WAV 文件并进行语音识别
+// TranscribeFile 读取音频文件 (WAV、FLAC、MP3) 并进行语音识别
 //
 // # Params:
 //
 //	wavPath: 音频文件路径
-func (e *Engine) TranscribeFile(wavPath string) (string, error) {
+//	opt: 转录可选参数 (Paraformer 忽略语言与任务)
+func (e *Engine) TranscribeFile(wavPath string, opt ...asr.TranscribeOption) (*asr.Result, error) {
 	wavBytes, err := os.ReadFile(wavPath)
 	if err != nil {
-		return "", fmt.Errorf("无法读取文件: %v", err)
+		return nil, fmt.Errorf("无法读取文件: %v", err)
 	}
-	return e.TranscribeBytes(wavBytes)
+	return e.TranscribeBytes(wavBytes, opt...)
 }
 
-// TranscribeBytes 读取 WAV 字节流并进行语音识别
+// TranscribeBytes 读取音频字节流 (WAV、FLAC、MP3) 并进行语音识别
 //
 // # Params:
 //
 //	wavBytes: 音频文件字节流
-func (e *Engine) TranscribeBytes(wavBytes []byte) (string, error) {
-	samples, err := parseWavBytes(wavBytes)
+//	opt: 转录可选参数 (Paraformer 忽略语言与任务)
+func (e *Engine) TranscribeBytes(wavBytes []byte, opt ...asr.TranscribeOption) (*asr.Result, error) {
+	if len(opt) > 0 && opt[0].SplitChannels {
+		return e.transcribeChannels(wavBytes, opt...)
+	}
+	samples, err := audio.DecodeMono(wavBytes, sampleRate)
+	if err != nil {
+		return nil, &speech.Error{Kind: speech.ErrInvalidAudio, Engine: engineName, Message: "无法解码音频数据", Err: err}
+	}
+	return e.Transcribe(samples, opt...)
+}
+
+// transcribeChannels 对每个声道分别识别，合并为按时间排序的对话
+func (e *Engine) transcribeChannels(wavBytes []byte, opt ...asr.TranscribeOption) (_ *asr.Result, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInvalidAudio, engineName, err) }()
+	buf, err := audio.Decode(wavBytes)
+	if err != nil {
+		return nil, fmt.Errorf("无法解码音频数据: %w", err)
+	}
+	result, err := asr.TranscribeChannels(context.Background(), buf, opt[0], chunkMaxDuration, func(ctx context.Context, samples []float32) (*asr.Result, error) {
+		return e.TranscribeContext(ctx, samples, opt...)
+	})
+	if errors.Is(err, asr.ErrEmptyStream) {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频数据为空", "")
+	}
+	return result, err
+}
+
+// TranscribeReader 从音频流增量读取并识别，切分为不超过 20 秒的片段后逐段识别
+//
+// 配置了 Splitter 时在语音段之间的静音处切分，否则在 10 到 20 秒之间能量最低的位置切分
+//
+// # Params:
+//
+//	r: 音频流，例如文件或网络连接
+//	format: 音频格式，例如 asr.FormatWAV、asr.FormatPCM16k
+//	opt: 转录可选参数 (Paraformer 忽略语言与任务)
+//
+// # Examples:
+//
+//	vadEngine, _ := vad.NewEngine(vad.DefaultConfig())
+//	cfg := paraformer.DefaultConfig()
+//	cfg.Splitter = vadEngine
+//	asrEngine, _ := paraformer.NewEngine(cfg)
+//	result, err := asrEngine.TranscribeReader(file, asr.FormatWAV)
+func (e *Engine) TranscribeReader(r io.Reader, format asr.AudioFormat, opt ...asr.TranscribeOption) (*asr.Result, error) {
+	return e.TranscribeReaderContext(context.Background(), r, format, opt...)
+}
+
+// TranscribeReaderContext 从音频流增量读取并识别，ctx 结束时在片段之间或片段内中止
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	r: 音频流
+//	format: 音频格式
+//	opt: 转录可选参数 (Paraformer 忽略语言与任务)
+func (e *Engine) TranscribeReaderContext(ctx context.Context, r io.Reader, format asr.AudioFormat, opt ...asr.TranscribeOption) (_ *asr.Result, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInvalidAudio, engineName, err) }()
+	chunks, err := asr.NewChunkReader(r, format, asr.ChunkOptions{MinDuration: chunkMinDuration, MaxDuration: chunkMaxDuration, Splitter: e.splitter})
 	if err != nil {
-		return "", fmt.Errorf("无法将 PCM 数据转换为 float32: %v", err)
+		return nil, fmt.Errorf("无法读取音频流: %w", err)
 	}
-	return e.Transcribe(samples)
+	result, err := asr.TranscribeChunks(ctx, chunks, func(ctx context.Context, samples []float32) (*asr.Result, error) {
+		return e.TranscribeContext(ctx, samples, opt...)
+	})
+	if errors.Is(err, asr.ErrEmptyStream) {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频流为空", "")
+	}
+	return result, err
 }
 
 // Transcribe 对 float32 音频样本数据进行识别
@@ -123,39 +276,79 @@ func (e *Engine) TranscribeBytes(wavBytes []byte) (string, error) {
 // # Params:
 //
 //	samples: 采样率为 16KHz 的单声道音频数据，范围 [-1, 1]
-func (e *Engine) Transcribe(samples []float32) (string, error) {
+//	opt: 转录可选参数 (Paraformer 忽略语言与任务)
+func (e *Engine) Transcribe(samples []float32, opt ...asr.TranscribeOption) (*asr.Result, error) {
+	return e.TranscribeContext(context.Background(), samples, opt...)
+}
+
+// TranscribeContext 对 float32 音频样本数据进行识别，ctx 结束时在特征帧之间或推理阶段之间中止
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	samples: 采样率为 16KHz 的单声道音频数据，范围 [-1, 1]
+//	opt: 转录可选参数 (Paraformer 忽略语言与任务)
+func (e *Engine) TranscribeContext(ctx context.Context, samples []float32, opt ...asr.TranscribeOption) (*asr.Result, error) {
+	return e.transcribe(ctx, samples, true)
+}
+
+// transcribe 识别一段音频，punctuate 为 false 时跳过标点预测
+func (e *Engine) transcribe(ctx context.Context, samples []float32, punctuate bool) (_ *asr.Result, err error) {
+	rec := speech.StartCall(e.observer, engineName, speech.OpTranscribe)
+	defer func() { rec.Finish(ctx, err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
 	if len(samples) == 0 {
-		return "", fmt.Errorf("输入的音频数据为空")
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频数据为空", "")
 	}
+	rec.SetAudio(len(samples), sampleRate)
 
 	// 特征提取
-	features, featLen, err := e.extractFeatures(samples)
+	features, featLen, err := e.extractFeatures(ctx, samples)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	rec.Stage("feature")
 
 	// 推理
-	tokenIDs, err := e.runInference(features, featLen)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	out, err := e.runInference(features, featLen)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// 解码
-	words := e.decode(tokenIDs)
+	words := decodeTokens(e.tokenMap, out.ids)
+	result := &asr.Result{Words: e.timedWords(out, int(featLen))}
+	rec.Stage("inference")
+	rec.AddTokens(0, len(words))
 
 	// 标点预测
-	if e.punctuationSession != nil {
+	if punctuate && e.punctuationSession != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		words, err = e.runPunctuationInference(words)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
+		rec.Stage("punctuation")
 	}
 
-	return e.join(words), nil
+	result.Text = joinWords(words)
+	return result, nil
+}
+
+// inferenceOutput 声学模型的输出
+type inferenceOutput struct {
+	ids    []int     // 每一步概率最大的 token
+	alphas []float32 // us_alphas，模型不支持时间戳时为空
+	peaks  []float32 // us_cif_peak，模型不支持时间戳时为空
 }
 
 // runInference 推理
-func (e *Engine) runInference(features []float32, featLen int32) ([]int, error) {
+func (e *Engine) runInference(features []float32, featLen int32) (*inferenceOutput, error) {
 	// 构建张量
 	tSpeech, err := ort.NewTensor([]int64{1, int64(featLen), 560}, features)
 	if err != nil {
@@ -173,13 +366,28 @@ func (e *Engine) runInference(features []float32, featLen int32) ([]int, error)
 		"speech_lengths": tLen,
 	}
 
+	// 热词偏置 [1, N+1, D]
+	if bias := e.bias.Load(); bias != nil {
+		tBias, err := ort.NewTensor([]int64{1, int64(len(bias.embed) / bias.dim), int64(bias.dim)}, bias.embed)
+		if err != nil {
+			return nil, fmt.Errorf("创建 bias_embed tensor 失败: %w", err)
+		}
+		defer tBias.Destroy()
+		inputValues["bias_embed"] = tBias
+	}
+
 	// 执行
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("推理运行失败: %w", err)
 	}
+	// 释放所有输出，包括未使用的 token_num 等
+	defer func() {
+		for _, v := range outputValues {
+			v.Destroy()
+		}
+	}()
 	outputValue := outputValues["logits"]
-	defer outputValue.Destroy()
 
 	// 获取结果
 	data, err := ort.GetTensorData[float32](outputValue)
@@ -192,7 +400,21 @@ func (e *Engine) runInference(features []float32, featLen int32) ([]int, error)
 		return nil, fmt.Errorf("输出结果维度异常: %w", err)
 	}
 
-	return getTokenIds(data, int(outputShape[1]), int(outputShape[2])), nil
+	out := &inferenceOutput{ids: getTokenIds(data, int(outputShape[1]), int(outputShape[2]))}
+
+	// 时间戳输出，数据由 ONNX Runtime 持有，需要在释放前复制
+	if e.timestamp {
+		alphas, err := ort.GetTensorData[float32](outputValues["us_alphas"])
+		if err != nil {
+			return nil, fmt.Errorf("获取 us_alphas 失败: %w", err)
+		}
+		peaks, err := ort.GetTensorData[float32](outputValues["us_cif_peak"])
+		if err != nil {
+			return nil, fmt.Errorf("获取 us_cif_peak 失败: %w", err)
+		}
+		out.alphas, out.peaks = slices.Clone(alphas), slices.Clone(peaks)
+	}
+	return out, nil
 }
 
 // 获取 token ids
@@ -220,13 +442,13 @@ func getTokenIds(tokenScores []float32, steps int, tokenSize int) []int {
 	return ids
 }
 
-// decode 解码，将 token ids 转换为文本
-func (e *Engine) decode(ids []int) []string {
+// decodeTokens 解码，将 token ids 转换为文本，子词 (@@) 合并为词
+func decodeTokens(tokenMap map[int]string, ids []int) []string {
 	var words []string
 	var currentWord strings.Builder
 
 	for _, idx := range ids {
-		if word, ok := e.tokenMap[idx]; ok {
+		if word, ok := tokenMap[idx]; ok {
 			if word == "<blank>" || word == "<s>" || word == "</s>" || word == "<unk>" {
 				continue
 			} else if strings.HasSuffix(word, "@@") {
@@ -244,6 +466,46 @@ func (e *Engine) decode(ids []int) []string {
 	return words
 }
 
+// timedWords 根据 CIF 峰值计算每个词的起止时间，子词 (@@) 合并为词，模型不支持时间戳时返回 nil
+func (e *Engine) timedWords(out *inferenceOutput, lfrFrames int) []asr.Word {
+	if out.peaks == nil {
+		return nil
+	}
+	// 参与对齐的 token 不含 <blank>、<s>、</s>
+	var tokens []string
+	for _, idx := range out.ids {
+		if word := e.tokenMap[idx]; word != "<blank>" && word != "<s>" && word != "</s>" {
+			tokens = append(tokens, word)
+		}
+	}
+	stamps := tokenTimestamps(out.alphas, out.peaks, len(tokens), lfrFrames)
+	if stamps == nil {
+		e.logger.Debug("CIF 峰值与 token 数不一致，忽略时间戳", "tokens", len(tokens))
+		return nil
+	}
+
+	var words []asr.Word
+	var current *asr.Word
+	for i, token := range tokens {
+		if token == "<unk>" {
+			continue
+		}
+		if current == nil {
+			current = &asr.Word{Start: stamps[i].start}
+		}
+		current.Text += strings.TrimSuffix(token, "@@")
+		current.End = stamps[i].end
+		if !strings.HasSuffix(token, "@@") {
+			words = append(words, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		words = append(words, *current)
+	}
+	return words
+}
+
 // loadPunctuationTokens 加载标点 JSON 词表
 func loadPunctuationTokens(path string) (map[string]int, error) {
 	data, err := os.ReadFile(path)
@@ -264,6 +526,23 @@ func loadPunctuationTokens(path string) (map[string]int, error) {
 
 // runPunctuationInference 执行标点预测
 func (e *Engine) runPunctuationInference(words []string) ([]string, error) {
+	puncts, err := e.predictPunctuation(words)
+	if err != nil {
+		return []string{}, err
+	}
+
+	var newWords []string
+	for i, p := range puncts {
+		newWords = append(newWords, words[i])
+		if p != "" {
+			newWords = append(newWords, p)
+		}
+	}
+	return newWords, nil
+}
+
+// predictPunctuation 预测每个词之后的标点，没有标点时为空
+func (e *Engine) predictPunctuation(words []string) ([]string, error) {
 	if e.punctuationSession == nil || len(words) == 0 {
 		return []string{}, nil
 	}
@@ -274,6 +553,7 @@ func (e *Engine) runPunctuationInference(words []string) ([]string, error) {
 		if id, ok := e.punctuationTokenMap[w]; ok {
 			inputIds[i] = int32(id)
 		} else {
+			e.logger.Debug("标点词表未包含该词", "token", w)
 			inputIds[i] = int32(e.punctuationTokenMap["<unk>"])
 		}
 	}
@@ -298,13 +578,12 @@ func (e *Engine) runPunctuationInference(words []string) ([]string, error) {
 	// 解析结果 [1, N, 6]
 	data, _ := ort.GetTensorData[float32](tLogits)
 	shape, _ := tLogits.GetShape()
-	numSteps := int(shape[1])
+	numSteps := min(int(shape[1]), len(words))
 	numClasses := int(shape[2])
 
-	var newWords []string
+	puncts := make([]string, len(words))
 	for i := 0; i < numSteps; i++ {
 		offset := i * numClasses
-		newWords = append(newWords, words[i])
 
 		// 计算当前位置概率最大的标点索引
 		maxIdx := 0
@@ -318,14 +597,14 @@ func (e *Engine) runPunctuationInference(words []string) ([]string, error) {
 		}
 
 		if maxIdx > 0 && maxIdx < len(e.punctuationList) && maxIdx != 1 {
-			newWords = append(newWords, e.punctuationList[maxIdx])
+			puncts[i] = e.punctuationList[maxIdx]
 		}
 	}
-	return newWords, nil
+	return puncts, nil
 }
 
-// join 单词拼接
-func (e *Engine) join(words []string) string {
+// joinWords 单词拼接，中文之间不加空格
+func joinWords(words []string) string {
 	var sb strings.Builder
 	for i, w := range words {
 		sb.WriteString(w)
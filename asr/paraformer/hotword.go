@@ -0,0 +1,190 @@
+package paraformer
+
+import (
+	"cmp"
+	"fmt"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// hotwordMaxLen 单个热词的最大 token 数，与 FunASR 导出的 SeACo 嵌入模型一致
+	hotwordMaxLen = 10
+	// hotwordMaxCount 热词数量上限，超出时保留权重较高的热词
+	hotwordMaxCount = 200
+	// sosID <s> 的 token id，作为 "无热词" 的占位项追加在热词列表末尾
+	sosID = 1
+)
+
+// Hotword 热词
+type Hotword struct {
+	Text   string  `json:"text"`   // 热词文本，例如产品名、人名，最多 10 个字
+	Weight float32 `json:"weight"` // (可选) 偏置强度，作用于热词嵌入的缩放，默认 1.0
+}
+
+// hotwordBias 编译后的热词偏置
+type hotwordBias struct {
+	hotwords []Hotword
+	embed    []float32 // [len(hotwords)+1, hiddenSize]，最后一项为 <s>
+	dim      int
+}
+
+// Hotwords 返回当前生效的热词
+func (e *Engine) Hotwords() []Hotword {
+	if b := e.bias.Load(); b != nil {
+		return slices.Clone(b.hotwords)
+	}
+	return nil
+}
+
+// SetHotwords 更新热词，无需重新加载模型
+//
+// 热词嵌入在调用时计算完成后整体替换，可以与正在进行的识别并发调用。
+// 超过 10 个字的热词会被忽略，数量超过上限时保留权重较高的热词
+//
+// # Params:
+//
+//	hotwords: 热词列表，为空时清除热词
+//
+// # Examples:
+//
+//	err := engine.SetHotwords([]paraformer.Hotword{{Text: "达摩院"}, {Text: "魔搭", Weight: 1.5}})
+func (e *Engine) SetHotwords(hotwords []Hotword) error {
+	if e.hotwordSession == nil {
+		return fmt.Errorf("模型不支持热词，请使用 SeACo-Paraformer 并设置 HotwordModelPath")
+	}
+	bias, err := e.compileHotwords(hotwords)
+	if err != nil {
+		return fmt.Errorf("计算热词嵌入失败: %w", err)
+	}
+	e.bias.Store(bias)
+	return nil
+}
+
+// compileHotwords 将热词转换为 token id 并运行嵌入模型
+//
+// 嵌入模型输入 [N+1, 10] 的 token id (不足补 0)，输出每个位置的嵌入，取每个热词最后一个 token 处的嵌入作为偏置
+func (e *Engine) compileHotwords(hotwords []Hotword) (*hotwordBias, error) {
+	var valid []Hotword
+	var ids [][]int32
+	for _, hw := range hotwords {
+		hw.Text = strings.TrimSpace(hw.Text)
+		if hw.Text == "" {
+			continue
+		}
+		if hw.Weight <= 0 {
+			hw.Weight = 1
+		}
+		tokens := e.hotwordTokens(hw.Text)
+		if len(tokens) > hotwordMaxLen {
+			e.logger.Warn("热词过长，已忽略", "hotword", hw.Text, "tokens", len(tokens))
+			continue
+		}
+		valid = append(valid, hw)
+		ids = append(ids, tokens)
+	}
+	if len(valid) > hotwordMaxCount {
+		order := make([]int, len(valid))
+		for i := range order {
+			order[i] = i
+		}
+		slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(valid[b].Weight, valid[a].Weight) })
+		order = order[:hotwordMaxCount]
+		slices.Sort(order)
+		e.logger.Warn("热词数量超出上限，已保留权重较高的热词", "count", len(valid), "max", hotwordMaxCount)
+		keptWords, keptIDs := make([]Hotword, len(order)), make([][]int32, len(order))
+		for i, idx := range order {
+			keptWords[i], keptIDs[i] = valid[idx], ids[idx]
+		}
+		valid, ids = keptWords, keptIDs
+	}
+	ids = append(ids, []int32{sosID})
+
+	n := len(ids)
+	input := make([]int32, n*hotwordMaxLen)
+	for i, tokens := range ids {
+		copy(input[i*hotwordMaxLen:], tokens)
+	}
+	tHotword, err := ort.NewTensor([]int64{int64(n), hotwordMaxLen}, input)
+	if err != nil {
+		return nil, fmt.Errorf("创建 hotword tensor 失败: %w", err)
+	}
+	defer tHotword.Destroy()
+
+	outputs, err := e.hotwordSession.Run(map[string]*ort.Value{e.hotwordSession.InputNames[0]: tHotword})
+	if err != nil {
+		return nil, fmt.Errorf("嵌入模型推理失败: %w", err)
+	}
+	defer destroyValues(outputs)
+	out := outputs[e.hotwordSession.OutputNames[0]]
+	data, err := ort.GetTensorData[float32](out)
+	if err != nil {
+		return nil, fmt.Errorf("获取热词嵌入失败: %w", err)
+	}
+	shape, err := out.GetShape()
+	if err != nil || len(shape) != 3 {
+		return nil, fmt.Errorf("热词嵌入维度异常: %v", shape)
+	}
+
+	// 输出布局为 [10, N, D] (FunASR 导出) 或 [N, 10, D]
+	dim := int(shape[2])
+	var at func(word, pos int) []float32
+	switch {
+	case int(shape[0]) == hotwordMaxLen && int(shape[1]) == n:
+		at = func(word, pos int) []float32 { return data[(pos*n+word)*dim:][:dim] }
+	case int(shape[0]) == n && int(shape[1]) == hotwordMaxLen:
+		at = func(word, pos int) []float32 { return data[(word*hotwordMaxLen+pos)*dim:][:dim] }
+	default:
+		return nil, fmt.Errorf("热词嵌入维度异常: %v", shape)
+	}
+	if len(data) < n*hotwordMaxLen*dim {
+		return nil, fmt.Errorf("热词嵌入数据长度异常: %d", len(data))
+	}
+	bias := &hotwordBias{hotwords: valid, embed: make([]float32, n*dim), dim: dim}
+	for i, tokens := range ids {
+		weight := float32(1)
+		if i < len(valid) {
+			weight = valid[i].Weight
+		}
+		dst := bias.embed[i*dim : (i+1)*dim]
+		for j, v := range at(i, len(tokens)-1) {
+			dst[j] = v * weight
+		}
+	}
+	return bias, nil
+}
+
+// hotwordTokens 将热词切分为 token id：中文按字，其他文字按空格分词，不在词表中的使用 <unk>
+func (e *Engine) hotwordTokens(text string) []int32 {
+	var tokens []string
+	for _, field := range strings.Fields(strings.ToLower(text)) {
+		var word strings.Builder
+		for _, r := range field {
+			if unicode.Is(unicode.Han, r) {
+				if word.Len() > 0 {
+					tokens = append(tokens, word.String())
+					word.Reset()
+				}
+				tokens = append(tokens, string(r))
+			} else {
+				word.WriteRune(r)
+			}
+		}
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+		}
+	}
+
+	ids := make([]int32, len(tokens))
+	for i, t := range tokens {
+		id, ok := e.vocab[t]
+		if !ok {
+			e.logger.Debug("热词包含词表外的字符，使用 <unk> 代替", "token", t)
+			id = e.vocab["<unk>"]
+		}
+		ids[i] = int32(id)
+	}
+	return ids
+}
@@ -0,0 +1,28 @@
+package paraformer
+
+import (
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestHotwordTokens(t *testing.T) {
+	e := &Engine{
+		vocab:  map[string]int{"<unk>": 0, "阿": 10, "里": 11, "hello": 20, "达": 30},
+		logger: slog.New(slog.DiscardHandler),
+	}
+	cases := []struct {
+		text string
+		want []int32
+	}{
+		{"阿里 Hello", []int32{10, 11, 20}},
+		{"hello达摩", []int32{20, 30, 0}},
+		{"阿x里", []int32{10, 0, 11}},
+		{"  ", []int32{}},
+	}
+	for _, c := range cases {
+		if got := e.hotwordTokens(c.text); !slices.Equal(got, c.want) {
+			t.Fatalf("%q: got %v, want %v", c.text, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package paraformer
+
+import (
+	"context"
+	"errors"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"github.com/up-zero/gotool/validator"
+	"strings"
+)
+
+const (
+	// punctuationWindow 长文本标点预测的窗口大小 (词数)
+	punctuationWindow = 200
+	// sentenceEnds 句末标点，长文本在此处切分窗口
+	sentenceEnds = "。？"
+)
+
+// TranscribeLong 识别任意时长的音频，例如会议录音与播客
+//
+// 音频按 VAD 或静音切分为不超过 20 秒的语音段后逐段识别，时间戳偏移到整段音频，
+// 标点模型对合并后的文本统一预测，避免在语音段边界处断句
+//
+// # Params:
+//
+//	samples: 采样率为 16KHz 的单声道音频数据，范围 [-1, 1]
+//	opt: 长音频识别可选参数，Splitter 可使用 *vad.Engine
+//
+// # Examples:
+//
+//	vadEngine, _ := vad.NewEngine(vad.DefaultConfig())
+//	result, err := asrEngine.TranscribeLong(samples, asr.LongOption{Splitter: vadEngine})
+//	for _, seg := range result.Segments {
+//		fmt.Println(seg.Start, seg.End, seg.Text)
+//	}
+func (e *Engine) TranscribeLong(samples []float32, opt ...asr.LongOption) (*asr.Result, error) {
+	return e.TranscribeLongContext(context.Background(), samples, opt...)
+}
+
+// TranscribeLongContext 与 TranscribeLong 相同，ctx 结束时在语音段之间或语音段内中止
+func (e *Engine) TranscribeLongContext(ctx context.Context, samples []float32, opt ...asr.LongOption) (*asr.Result, error) {
+	return transcribeLong(ctx, samples, 1, opt, func(_ context.Context, fn func(e *Engine) error) error { return fn(e) })
+}
+
+// TranscribeLongWithPool 使用引擎池并行识别长音频中的语音段，并行度为池的实例数量
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	pool: 引擎池
+//	samples: 采样率为 16KHz 的单声道音频数据，范围 [-1, 1]
+//	opt: 长音频识别可选参数
+//
+// # Examples:
+//
+//	pool, err := speech.NewPool(speech.PoolConfig{Size: 4}, func() (*paraformer.Engine, error) {
+//		return paraformer.NewEngine(cfg)
+//	})
+//	result, err := paraformer.TranscribeLongWithPool(ctx, pool, samples)
+func TranscribeLongWithPool(ctx context.Context, pool *speech.Pool[*Engine], samples []float32, opt ...asr.LongOption) (*asr.Result, error) {
+	return transcribeLong(ctx, samples, pool.Stats().Size, opt, pool.Do)
+}
+
+// transcribeLong 长音频识别流程，do 获取一个引擎实例执行 fn，例如 Pool.Do
+func transcribeLong(ctx context.Context, samples []float32, workers int, opt []asr.LongOption, do func(ctx context.Context, fn func(e *Engine) error) error) (_ *asr.Result, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	var splitter asr.Splitter
+	if len(opt) > 0 {
+		splitter = opt[0].Splitter
+	}
+
+	result, err := asr.TranscribeLong(ctx, samples, splitter, chunkMaxDuration, workers,
+		func(ctx context.Context, samples []float32) (result *asr.Result, err error) {
+			err = do(ctx, func(e *Engine) error {
+				result, err = e.transcribe(ctx, samples, false)
+				return err
+			})
+			return result, err
+		},
+		func(ctx context.Context, texts []string) (punctuated []string, err error) {
+			err = do(ctx, func(e *Engine) error {
+				punctuated, err = e.punctuateTexts(ctx, texts)
+				return err
+			})
+			return punctuated, err
+		})
+	if errors.Is(err, asr.ErrEmptyStream) {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频数据为空", "")
+	}
+	return result, err
+}
+
+// punctuateTexts 将各语音段的文本合并后分窗口预测标点，返回与输入一一对应的文本
+//
+// 每个窗口只保留到最后一个句末标点为止的结果，其余部分并入下一个窗口重新预测
+func (e *Engine) punctuateTexts(ctx context.Context, texts []string) ([]string, error) {
+	if e.punctuationSession == nil {
+		return texts, nil
+	}
+
+	var words []string
+	var owners []int // 词所属的文本序号
+	for i, text := range texts {
+		for _, w := range splitWords(text) {
+			words = append(words, w)
+			owners = append(owners, i)
+		}
+	}
+
+	puncts := make([]string, len(words))
+	for start := 0; start < len(words); {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		end := min(start+punctuationWindow, len(words))
+		out, err := e.predictPunctuation(words[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if end < len(words) {
+			for i := len(out) - 1; i >= 0; i-- {
+				if out[i] != "" && strings.Contains(sentenceEnds, out[i]) {
+					end = start + i + 1
+					break
+				}
+			}
+		}
+		copy(puncts[start:end], out)
+		start = end
+	}
+
+	parts := make([][]string, len(texts))
+	for i, w := range words {
+		parts[owners[i]] = append(parts[owners[i]], w)
+		if puncts[i] != "" {
+			parts[owners[i]] = append(parts[owners[i]], puncts[i])
+		}
+	}
+	punctuated := make([]string, len(texts))
+	for i := range texts {
+		punctuated[i] = joinWords(parts[i])
+	}
+	return punctuated, nil
+}
+
+// splitWords 将识别文本拆分为标点模型的词: 中文按字，其余按空格
+func splitWords(text string) []string {
+	var words []string
+	for _, field := range strings.Fields(text) {
+		if !validator.IsChinese(field) {
+			words = append(words, field)
+			continue
+		}
+		for _, r := range field {
+			words = append(words, string(r))
+		}
+	}
+	return words
+}
@@ -0,0 +1,477 @@
+package paraformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"github.com/getcharzp/go-speech/internal/fbank"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"github.com/up-zero/gotool/convertutil"
+	"github.com/up-zero/gotool/mediautil"
+	"log/slog"
+	"math"
+	"slices"
+)
+
+// OnlineEngine 封装了流式 Paraformer 的编码器、解码器 ONNX 运行时和相关资源
+//
+// OnlineEngine 只持有只读的模型与词表，识别状态保存在 Stream 中，
+// 不同的 Stream 可以在多个 goroutine 中共享同一个 OnlineEngine，单个 Stream 不是并发安全的
+type OnlineEngine struct {
+	onnx     *speech.OnnxConfig
+	encoder  *ort.Session
+	decoder  *ort.Session
+	tokenMap map[int]string
+	negMean  []float32 // CMVN 均值
+	invStd   []float32 // CMVN 方差倒数
+
+	chunk         [3]int    // [历史, 当前, 前瞻] LFR 帧数
+	hiddenSize    int       // 编码器输出维度
+	fsmnOrder     int       // 解码器 FSMN 缓存长度
+	numBlocks     int       // 解码器 FSMN 块数，即 in_cache_* 输入的个数
+	cifThreshold  float32   // CIF 触发阈值
+	tailThreshold float32   // 结束时补充的 CIF 权重
+	invTimescales []float64 // 正弦位置编码的频率
+
+	logger   *slog.Logger
+	observer speech.Observer
+}
+
+// NewOnlineEngine 初始化流式 Paraformer ASR 引擎
+//
+// 失败时返回的错误满足 errors.Is(err, speech.ErrModelLoad)
+//
+// # Examples:
+//
+//	engine, err := paraformer.NewOnlineEngine(paraformer.DefaultOnlineConfig())
+//	stream := engine.NewStream()
+//	for chunk := range audioChunks {
+//		_ = stream.AcceptWaveform(chunk)
+//		fmt.Println(stream.PartialResult())
+//	}
+//	result, err := stream.FinalResult()
+func NewOnlineEngine(cfg OnlineConfig) (_ *OnlineEngine, err error) {
+	defer func() { err = speech.WrapError(speech.ErrModelLoad, onlineEngineName, err) }()
+
+	if len(cfg.ChunkSize) != 3 || slices.Min(cfg.ChunkSize) < 0 || cfg.ChunkSize[1] <= 0 {
+		return nil, fmt.Errorf("chunk_size 必须为 3 个非负整数且当前块大于 0: %v", cfg.ChunkSize)
+	}
+	if cfg.EncoderOutputSize <= 0 || cfg.DecoderKernelSize < 2 || cfg.CifThreshold <= 0 {
+		return nil, fmt.Errorf("模型结构参数无效: encoder_output_size=%d, decoder_kernel_size=%d, cif_threshold=%v",
+			cfg.EncoderOutputSize, cfg.DecoderKernelSize, cfg.CifThreshold)
+	}
+
+	oc := new(speech.OnnxConfig)
+	_ = convertutil.CopyProperties(cfg, oc)
+
+	// 初始化 ONNX
+	if err := oc.New(); err != nil {
+		return nil, err
+	}
+	engine := &OnlineEngine{
+		onnx:          oc,
+		chunk:         [3]int(cfg.ChunkSize),
+		hiddenSize:    cfg.EncoderOutputSize,
+		fsmnOrder:     cfg.DecoderKernelSize - 1,
+		cifThreshold:  cfg.CifThreshold,
+		tailThreshold: cfg.TailThreshold,
+		logger:        speech.EngineLogger(cfg.Logger, onlineEngineName),
+		observer:      cfg.Observer,
+	}
+
+	// 加载资源 (Tokens 和 CMVN)
+	if engine.tokenMap, err = loadTokens(cfg.TokensPath); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("加载词表失败: %w", err)
+	}
+	if engine.negMean, engine.invStd, err = fbank.LoadCMVN(cfg.CMVNPath); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("加载 CMVN 失败: %w", err)
+	}
+
+	// 创建编码器会话
+	if engine.encoder, err = oc.NewSession(cfg.EncoderModelPath); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("创建编码器会话失败: %w", err)
+	}
+	if err := speech.CheckSession(engine.encoder, []string{"speech", "speech_lengths"}, []string{"enc", "enc_len", "alphas"}); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("编码器: %w", err)
+	}
+
+	// 创建解码器会话，FSMN 块数由 in_cache_* 输入的个数确定
+	if engine.decoder, err = oc.NewSession(cfg.DecoderModelPath); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("创建解码器会话失败: %w", err)
+	}
+	for slices.Contains(engine.decoder.InputNames, fmt.Sprintf("in_cache_%d", engine.numBlocks)) {
+		engine.numBlocks++
+	}
+	inputs := []string{"enc", "enc_len", "acoustic_embeds", "acoustic_embeds_len"}
+	outputs := []string{"logits"}
+	for i := 0; i < engine.numBlocks; i++ {
+		inputs = append(inputs, fmt.Sprintf("in_cache_%d", i))
+		outputs = append(outputs, fmt.Sprintf("out_cache_%d", i))
+	}
+	if err := speech.CheckSession(engine.decoder, inputs, outputs); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("解码器: %w", err)
+	}
+	if engine.numBlocks == 0 {
+		engine.Destroy()
+		return nil, fmt.Errorf("解码器缺少 in_cache_* 输入，不是流式模型")
+	}
+
+	engine.invTimescales = invTimescales(melBins * lfrM)
+	return engine, nil
+}
+
+// invTimescales 正弦位置编码的频率: inv[i] = exp(-i * ln(10000) / (depth/2 - 1))
+func invTimescales(depth int) []float64 {
+	inv := make([]float64, depth/2)
+	for i := range inv {
+		inv[i] = math.Exp(-float64(i) * math.Log(10000) / float64(depth/2-1))
+	}
+	return inv
+}
+
+// Destroy 释放相关资源
+func (e *OnlineEngine) Destroy() {
+	if e.encoder != nil {
+		e.encoder.Destroy()
+	}
+	if e.decoder != nil {
+		e.decoder.Destroy()
+	}
+	if e.onnx != nil {
+		e.onnx.Destroy()
+	}
+}
+
+// Close 释放相关资源，实现 io.Closer 接口
+func (e *OnlineEngine) Close() error {
+	e.Destroy()
+	return nil
+}
+
+// Stream 流式识别会话，保存特征提取、编码器分块、CIF 与解码器 FSMN 的缓存
+type Stream struct {
+	e *OnlineEngine
+
+	// 特征提取
+	wave      []float32    // 预加重后尚未成帧的样本
+	last      float32      // 上一个原始样本，用于跨批次预加重
+	fbank     [][]float32  // 尚未组成 LFR 帧的 FBank 帧
+	padded    bool         // 是否已在开头补齐 LFR 窗口
+	position  int          // 已输出的 LFR 帧数，用于位置编码
+	fftBuffer []complex128 // FFT 缓冲
+
+	// frames 编码器输入窗口: 开头 chunk[0] 帧为历史，其后为尚未识别的帧
+	frames [][]float32
+
+	// CIF 与解码器状态
+	cifFrame []float32   // 尚未触发的加权隐状态之和
+	cifAlpha float32     // 尚未触发的权重之和
+	caches   [][]float32 // 每个 FSMN 块的缓存 [hiddenSize, fsmnOrder]
+	ids      []int       // 已识别的 token
+}
+
+// NewStream 创建流式识别会话
+func (e *OnlineEngine) NewStream() *Stream {
+	s := &Stream{e: e, fftBuffer: make([]complex128, fbank.FFTSize)}
+	s.reset()
+	return s
+}
+
+// reset 清空识别状态，编码器窗口以 chunk[0] + chunk[2] 个全零帧开始
+func (s *Stream) reset() {
+	e := s.e
+	s.wave, s.last, s.fbank, s.padded, s.position = s.wave[:0], 0, nil, false, 0
+	s.frames = make([][]float32, e.chunk[0]+e.chunk[2])
+	for i := range s.frames {
+		s.frames[i] = make([]float32, melBins*lfrM)
+	}
+	s.cifFrame, s.cifAlpha = make([]float32, e.hiddenSize), 0
+	s.caches = make([][]float32, e.numBlocks)
+	for i := range s.caches {
+		s.caches[i] = make([]float32, e.hiddenSize*e.fsmnOrder)
+	}
+	s.ids = nil
+}
+
+// AcceptWaveform 输入音频样本，每凑满一个分块 (默认 600ms) 进行一次识别
+//
+// # Params:
+//
+//	samples: 采样率为 16KHz 的单声道音频数据，范围 [-1, 1]，长度任意
+func (s *Stream) AcceptWaveform(samples []float32) error {
+	return s.AcceptWaveformContext(context.Background(), samples)
+}
+
+// AcceptWaveformContext 与 AcceptWaveform 相同，ctx 结束时在分块之间中止
+//
+// 中止时已计算的特征保留在会话中，下次调用时继续识别
+func (s *Stream) AcceptWaveformContext(ctx context.Context, samples []float32) (err error) {
+	rec := speech.StartCall(s.e.observer, onlineEngineName, speech.OpTranscribe)
+	defer func() { rec.Finish(ctx, err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, onlineEngineName, err) }()
+	rec.SetAudio(len(samples), sampleRate)
+
+	s.appendFeatures(samples, false)
+	rec.Stage("feature")
+	c := s.e.chunk
+	for len(s.frames) >= c[0]+c[1]+c[2] {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.step(rec, c[1], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PartialResult 返回当前已识别的文本，不会触发推理
+func (s *Stream) PartialResult() *asr.Result {
+	return &asr.Result{Text: joinWords(decodeTokens(s.e.tokenMap, s.ids))}
+}
+
+// FinalResult 处理剩余的音频并返回完整结果，随后重置会话，可继续用于下一句
+func (s *Stream) FinalResult() (*asr.Result, error) {
+	return s.FinalResultContext(context.Background())
+}
+
+// FinalResultContext 与 FinalResult 相同，ctx 结束时在分块之间中止，中止后会话同样被重置
+func (s *Stream) FinalResultContext(ctx context.Context) (_ *asr.Result, err error) {
+	rec := speech.StartCall(s.e.observer, onlineEngineName, speech.OpTranscribe)
+	defer func() { rec.Finish(ctx, err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, onlineEngineName, err) }()
+	defer s.reset()
+
+	s.appendFeatures(nil, true)
+	rec.Stage("feature")
+	c := s.e.chunk
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pending := len(s.frames) - c[0]
+		last := pending <= c[1]
+		if err := s.step(rec, min(pending, c[1]), last); err != nil {
+			return nil, err
+		}
+		if last {
+			break
+		}
+	}
+	return s.PartialResult(), nil
+}
+
+// appendFeatures 增量计算特征，流程: Wave -> FilterBank -> LFR -> CMVN -> 缩放与位置编码
+//
+// final 为 true 时使用最后一帧补齐剩余的 LFR 窗口
+func (s *Stream) appendFeatures(samples []float32, final bool) {
+	e := s.e
+	for _, v := range samples {
+		s.wave = append(s.wave, v-fbank.PreEmphasis*s.last)
+		s.last = v
+	}
+	start := 0
+	for ; start+fbank.FrameLen <= len(s.wave); start += fbank.FrameShift {
+		s.fbank = append(s.fbank, fbank.Frame(s.wave[start:start+fbank.FrameLen], s.fftBuffer))
+	}
+	s.wave = s.wave[:copy(s.wave, s.wave[start:])]
+
+	// 与 FunASR 一致，开头重复第一帧 (lfrM-1)/2 次
+	if !s.padded && len(s.fbank) > 0 {
+		pad := slices.Repeat([][]float32{s.fbank[0]}, (lfrM-1)/2)
+		s.fbank = append(pad, s.fbank...)
+		s.padded = true
+	}
+
+	scale := float32(math.Sqrt(float64(e.hiddenSize)))
+	half := len(e.invTimescales)
+	for len(s.fbank) >= lfrM || final && len(s.fbank) > 0 {
+		frame := make([]float32, 0, melBins*lfrM)
+		for j := 0; j < lfrM; j++ {
+			frame = append(frame, s.fbank[min(j, len(s.fbank)-1)]...)
+		}
+		s.fbank = s.fbank[min(lfrN, len(s.fbank)):]
+
+		mediautil.ApplyCMVN([][]float32{frame}, e.negMean, e.invStd)
+		s.position++
+		for i, inv := range e.invTimescales {
+			frame[i] = frame[i]*scale + float32(math.Sin(float64(s.position)*inv))
+			frame[half+i] = frame[half+i]*scale + float32(math.Cos(float64(s.position)*inv))
+		}
+		s.frames = append(s.frames, frame)
+	}
+}
+
+// step 识别窗口中从 chunk[0] 开始的 n 帧，其后最多 chunk[2] 帧作为前瞻，不足的部分补零
+//
+// last 为 true 时在 CIF 末尾补充 tailThreshold 的权重，使最后一个 token 触发
+func (s *Stream) step(rec *speech.Recorder, n int, last bool) error {
+	e := s.e
+	size := e.chunk[0] + e.chunk[1] + e.chunk[2]
+	dim := melBins * lfrM
+	features := make([]float32, size*dim)
+	for i, frame := range s.frames[:min(size, len(s.frames))] {
+		copy(features[i*dim:], frame)
+	}
+
+	// 编码器
+	tSpeech, err := ort.NewTensor([]int64{1, int64(size), int64(dim)}, features)
+	if err != nil {
+		return fmt.Errorf("创建 speech tensor 失败: %w", err)
+	}
+	defer tSpeech.Destroy()
+	tLen, err := ort.NewTensor([]int64{1}, []int32{int32(size)})
+	if err != nil {
+		return fmt.Errorf("创建 length tensor 失败: %w", err)
+	}
+	defer tLen.Destroy()
+	encOut, err := e.encoder.Run(map[string]*ort.Value{"speech": tSpeech, "speech_lengths": tLen})
+	if err != nil {
+		return fmt.Errorf("编码器推理失败: %w", err)
+	}
+	defer destroyValues(encOut)
+
+	hidden, err := ort.GetTensorData[float32](encOut["enc"])
+	if err != nil {
+		return fmt.Errorf("获取编码器输出失败: %w", err)
+	}
+	alphas, err := ort.GetTensorData[float32](encOut["alphas"])
+	if err != nil {
+		return fmt.Errorf("获取 alphas 失败: %w", err)
+	}
+
+	// 仅当前块参与 CIF，历史与前瞻帧的权重置零
+	weights := make([]float32, len(alphas))
+	for t := e.chunk[0]; t < min(e.chunk[0]+n, len(alphas)); t++ {
+		weights[t] = alphas[t]
+	}
+	embeds := s.cif(hidden, weights, last)
+	rec.Stage("encoder")
+
+	s.frames = s.frames[n:]
+	if len(embeds) == 0 {
+		return nil
+	}
+	numIds := len(s.ids)
+	if err := s.decode(encOut, embeds); err != nil {
+		return err
+	}
+	rec.Stage("decoder")
+	rec.AddTokens(0, len(s.ids)-numIds)
+	return nil
+}
+
+// cif 连续积分发放，返回本次触发的声学嵌入 [N, hiddenSize]，未触发的部分保存到缓存
+func (s *Stream) cif(hidden, alphas []float32, last bool) []float32 {
+	e := s.e
+	d := e.hiddenSize
+	if last {
+		hidden = append(slices.Clip(hidden), make([]float32, d)...)
+		alphas = append(alphas, e.tailThreshold)
+	}
+
+	var embeds []float32
+	integrate, frame := s.cifAlpha, s.cifFrame
+	for t, alpha := range alphas {
+		h := hidden[t*d : (t+1)*d]
+		if alpha+integrate < e.cifThreshold {
+			integrate += alpha
+			for i := range frame {
+				frame[i] += alpha * h[i]
+			}
+			continue
+		}
+		w := e.cifThreshold - integrate
+		for i := range frame {
+			frame[i] += w * h[i]
+		}
+		embeds = append(embeds, frame...)
+		integrate += alpha - e.cifThreshold
+		for i := range frame {
+			frame[i] = integrate * h[i]
+		}
+	}
+	s.cifAlpha, s.cifFrame = integrate, frame
+	return embeds
+}
+
+// decode 运行解码器，更新 FSMN 缓存并追加识别的 token
+func (s *Stream) decode(encOut map[string]*ort.Value, embeds []float32) error {
+	e := s.e
+	numTokens := len(embeds) / e.hiddenSize
+
+	tEmbeds, err := ort.NewTensor([]int64{1, int64(numTokens), int64(e.hiddenSize)}, embeds)
+	if err != nil {
+		return fmt.Errorf("创建 acoustic_embeds tensor 失败: %w", err)
+	}
+	defer tEmbeds.Destroy()
+	tEmbedsLen, err := ort.NewTensor([]int64{1}, []int32{int32(numTokens)})
+	if err != nil {
+		return fmt.Errorf("创建 acoustic_embeds_len tensor 失败: %w", err)
+	}
+	defer tEmbedsLen.Destroy()
+
+	inputs := map[string]*ort.Value{
+		"enc":                 encOut["enc"],
+		"enc_len":             encOut["enc_len"],
+		"acoustic_embeds":     tEmbeds,
+		"acoustic_embeds_len": tEmbedsLen,
+	}
+	for i, cache := range s.caches {
+		t, err := ort.NewTensor([]int64{1, int64(e.hiddenSize), int64(e.fsmnOrder)}, cache)
+		if err != nil {
+			return fmt.Errorf("创建 in_cache_%d tensor 失败: %w", i, err)
+		}
+		defer t.Destroy()
+		inputs[fmt.Sprintf("in_cache_%d", i)] = t
+	}
+
+	decOut, err := e.decoder.Run(inputs)
+	if err != nil {
+		return fmt.Errorf("解码器推理失败: %w", err)
+	}
+	defer destroyValues(decOut)
+
+	// 缓存只保留最后 fsmnOrder 列
+	for i, cache := range s.caches {
+		out := decOut[fmt.Sprintf("out_cache_%d", i)]
+		data, err := ort.GetTensorData[float32](out)
+		if err != nil {
+			return fmt.Errorf("获取 out_cache_%d 失败: %w", i, err)
+		}
+		shape, err := out.GetShape() // [1, hiddenSize, L]
+		if err != nil || len(shape) != 3 || int(shape[1]) != e.hiddenSize || int(shape[2]) < e.fsmnOrder {
+			return fmt.Errorf("out_cache_%d 维度异常: %v", i, shape)
+		}
+		l := int(shape[2])
+		for d := 0; d < e.hiddenSize; d++ {
+			copy(cache[d*e.fsmnOrder:(d+1)*e.fsmnOrder], data[d*l+l-e.fsmnOrder:(d+1)*l])
+		}
+	}
+
+	logits := decOut["logits"]
+	data, err := ort.GetTensorData[float32](logits)
+	if err != nil {
+		return fmt.Errorf("获取输出数据失败: %w", err)
+	}
+	shape, err := logits.GetShape() // [1, N, TokenSize]
+	if err != nil || len(shape) != 3 {
+		return fmt.Errorf("输出结果维度异常: %v", shape)
+	}
+	s.ids = append(s.ids, getTokenIds(data, min(int(shape[1]), numTokens), int(shape[2]))...)
+	return nil
+}
+
+// destroyValues 释放推理输出
+func destroyValues(values map[string]*ort.Value) {
+	for _, v := range values {
+		v.Destroy()
+	}
+}
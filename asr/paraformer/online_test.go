@@ -0,0 +1,101 @@
+package paraformer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// testOnlineEngine 不加载模型的流式引擎，只用于特征提取与 CIF
+func testOnlineEngine() *OnlineEngine {
+	dim := melBins * lfrM
+	return &OnlineEngine{
+		negMean:       make([]float32, dim),
+		invStd:        slices.Repeat([]float32{1}, dim),
+		chunk:         [3]int{5, 10, 5},
+		hiddenSize:    4,
+		fsmnOrder:     10,
+		cifThreshold:  1,
+		tailThreshold: 0.45,
+		invTimescales: invTimescales(dim),
+	}
+}
+
+func TestStreamContinuity(t *testing.T) {
+	samples := make([]float32, 3*16000+123)
+	for i := range samples {
+		samples[i] = float32(0.3*math.Sin(float64(i)*0.05) + 0.1*math.Sin(float64(i)*0.011))
+	}
+	e := testOnlineEngine()
+
+	whole := e.NewStream()
+	whole.appendFeatures(samples, false)
+	whole.appendFeatures(nil, true)
+
+	chunked := e.NewStream()
+	for i := 0; i < len(samples); i += 357 {
+		chunked.appendFeatures(samples[i:min(i+357, len(samples))], false)
+	}
+	chunked.appendFeatures(nil, true)
+
+	if len(whole.frames) != len(chunked.frames) {
+		t.Fatalf("frames: %d vs %d", len(whole.frames), len(chunked.frames))
+	}
+	for i := range whole.frames {
+		if !slices.Equal(whole.frames[i], chunked.frames[i]) {
+			t.Fatalf("frame %d differs", i)
+		}
+	}
+
+	// 以特征代替编码器输出: 隐状态取前 hiddenSize 维，权重由帧能量决定
+	d := e.hiddenSize
+	frames := whole.frames[e.chunk[0]+e.chunk[2]:]
+	var hidden, alphas []float32
+	for _, f := range frames {
+		hidden = append(hidden, f[:d]...)
+		var sum float64
+		for _, v := range f {
+			sum += math.Abs(float64(v))
+		}
+		alphas = append(alphas, float32(math.Mod(sum, 0.6)))
+	}
+
+	oneShot := e.NewStream().cif(slices.Clone(hidden), slices.Clone(alphas), true)
+
+	s := e.NewStream()
+	var streamed []float32
+	n := e.chunk[1]
+	for t := 0; t < len(alphas); t += n {
+		end := min(t+n, len(alphas))
+		streamed = append(streamed, s.cif(hidden[t*d:end*d], alphas[t:end], end == len(alphas))...)
+	}
+
+	if len(oneShot) == 0 || len(oneShot)/d != len(streamed)/d {
+		t.Fatalf("tokens: %d vs %d", len(oneShot)/d, len(streamed)/d)
+	}
+	for i := range oneShot {
+		if math.Abs(float64(oneShot[i]-streamed[i])) > 1e-4 {
+			t.Fatalf("embedding %d: %v vs %v", i, oneShot[i], streamed[i])
+		}
+	}
+}
+
+func TestStreamContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := testOnlineEngine().NewStream()
+	err := s.AcceptWaveformContext(ctx, make([]float32, 2*16000))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	// 中止前的特征保留在会话中
+	if len(s.frames) == 0 {
+		t.Fatal("frames dropped")
+	}
+	if _, err := s.FinalResultContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,100 @@
+package paraformer
+
+import (
+	"math"
+	"time"
+)
+
+// 时间戳计算参数，与 FunASR 的 ts_prediction_lfr6_standard 一致
+const (
+	// lfrShift LFR 帧移，每帧拼接 6 个 10ms 的 Fbank 帧
+	lfrShift = 60 * time.Millisecond
+	// cifThreshold CIF 触发阈值
+	cifThreshold = 1 - 1e-4
+	// peakShift CIF 峰值相对 token 起点的偏移 (上采样帧)
+	peakShift = -1.5
+	// startEndThreshold 首尾超过该帧数时视为静音
+	startEndThreshold = 5
+	// maxTokenDuration 单个 token 的最大时长 (上采样帧)，超出的部分视为静音
+	maxTokenDuration = 30
+)
+
+// span token 的起止时间
+type span struct {
+	start, end time.Duration
+}
+
+// tokenTimestamps 根据 CIF 峰值计算每个 token 的起止时间
+//
+// 时间戳模型在 token 开始处触发峰值，两个峰值之间为前一个 token 的时长，峰值数应为 token 数 + 1 (</s>)；
+// 数量不一致时按 token 数归一化 alphas 后重新积分得到峰值
+//
+// # Params:
+//
+//	alphas: us_alphas 输出，上采样后的 CIF 权重
+//	peaks: us_cif_peak 输出，上采样后的 CIF 峰值
+//	numTokens: 不含 </s> 的 token 数
+//	lfrFrames: 编码器输入的 LFR 帧数，用于确定上采样倍率
+func tokenTimestamps(alphas, peaks []float32, numTokens, lfrFrames int) []span {
+	if numTokens == 0 || len(peaks) == 0 || lfrFrames == 0 {
+		return nil
+	}
+	upsample := max(int(math.Round(float64(len(peaks))/float64(lfrFrames))), 1)
+	frameRate := float64(lfrShift) / float64(upsample)
+	at := func(frame float64) time.Duration {
+		return time.Duration(max(frame, 0) * frameRate)
+	}
+
+	fires := firePlaces(peaks)
+	if len(fires) != numTokens+1 && len(alphas) == len(peaks) {
+		var sum float64
+		for _, a := range alphas {
+			sum += float64(a)
+		}
+		if sum > 0 {
+			scale := float64(numTokens+1) / sum
+			var integrate float64
+			rescaled := make([]float32, len(alphas))
+			for t, a := range alphas {
+				integrate += float64(a) * scale
+				rescaled[t] = float32(integrate)
+				if integrate >= cifThreshold {
+					integrate--
+				}
+			}
+			fires = firePlaces(rescaled)
+		}
+	}
+	if len(fires) < numTokens+1 {
+		return nil
+	}
+
+	numFrames := float64(len(peaks))
+	stamps := make([]span, numTokens)
+	for i := range stamps {
+		end := fires[i+1]
+		if i < numTokens-1 && end-fires[i] >= maxTokenDuration {
+			end = fires[i] + maxTokenDuration
+		}
+		stamps[i] = span{at(fires[i]), at(end)}
+	}
+	// 尾部静音较长时最后一个 token 结束于峰值与音频末尾的中点
+	last := fires[numTokens]
+	if numFrames-last > startEndThreshold {
+		stamps[numTokens-1].end = at((numFrames + last) / 2)
+	} else {
+		stamps[numTokens-1].end = at(numFrames)
+	}
+	return stamps
+}
+
+// firePlaces 返回达到触发阈值的帧位置，已加上峰值偏移
+func firePlaces(peaks []float32) []float64 {
+	var fires []float64
+	for t, p := range peaks {
+		if p >= cifThreshold {
+			fires = append(fires, float64(t)+peakShift)
+		}
+	}
+	return fires
+}
@@ -0,0 +1,85 @@
+package paraformer
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// fire 生成长度为 n 的峰值序列，在 frames 处触发
+func fire(n int, frames ...int) []float32 {
+	peaks := make([]float32, n)
+	for _, t := range frames {
+		peaks[t] = 1
+	}
+	return peaks
+}
+
+func TestTokenTimestamps(t *testing.T) {
+	ms := time.Millisecond
+	uniform := make([]float32, 30)
+	for i := range uniform {
+		uniform[i] = 0.1
+	}
+
+	cases := []struct {
+		name      string
+		alphas    []float32
+		peaks     []float32
+		numTokens int
+		lfrFrames int
+		want      []span
+	}{
+		{
+			name:      "峰值数等于 token 数 + 1",
+			peaks:     fire(30, 3, 10, 20),
+			numTokens: 2,
+			lfrFrames: 10,
+			want:      []span{{30 * ms, 170 * ms}, {170 * ms, 485 * ms}},
+		},
+		{
+			name:      "峰值少于 token 数时按 alphas 重新积分",
+			alphas:    uniform,
+			peaks:     fire(30, 3, 20),
+			numTokens: 2,
+			lfrFrames: 10,
+			want:      []span{{150 * ms, 350 * ms}, {350 * ms, 600 * ms}},
+		},
+		{
+			name:      "峰值不足且没有 alphas",
+			peaks:     fire(30, 3, 20),
+			numTokens: 2,
+			lfrFrames: 10,
+		},
+		{
+			name:      "过长的 token 截断，起点不早于 0",
+			peaks:     fire(90, 1, 50, 60),
+			numTokens: 2,
+			lfrFrames: 30,
+			want:      []span{{0, 590 * ms}, {970 * ms, 1485 * ms}},
+		},
+		{
+			name:      "没有 token",
+			peaks:     fire(30, 3),
+			lfrFrames: 10,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := tokenTimestamps(c.alphas, c.peaks, c.numTokens, c.lfrFrames)
+			if !slices.Equal(got, c.want) {
+				t.Fatalf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestFirePlaces(t *testing.T) {
+	got := firePlaces([]float32{0.5, 1, 0.99995, 0.2, 0.9})
+	if want := []float64{-0.5, 0.5}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := firePlaces(make([]float32, 4)); got != nil {
+		t.Fatalf("got %v, want nil", got)
+	}
+}
@@ -0,0 +1,307 @@
+package asr
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/getcharzp/go-speech/audio"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SampleRate ASR 引擎的输入采样率
+const SampleRate = 16000
+
+// AudioFormat 流式输入的音频格式
+type AudioFormat struct {
+	Container  audio.Container // 封装格式: wav 从流中读取头部，raw (默认) 使用下列参数
+	Encoding   audio.Encoding  // 裸数据的样本编码，默认 s16le
+	SampleRate int             // 裸数据的采样率，默认 16000
+	Channels   int             // 裸数据的声道数，默认 1
+}
+
+// 常用输入格式
+var (
+	// FormatWAV 带头部的 WAV 流，格式从头部读取
+	FormatWAV = AudioFormat{Container: audio.ContainerWAV}
+	// FormatPCM16k 16KHz 单声道 16 位裸 PCM
+	FormatPCM16k = AudioFormat{Encoding: audio.EncodingS16LE, SampleRate: SampleRate, Channels: 1}
+)
+
+// NewReader 按照格式打开音频流
+func (f AudioFormat) NewReader(r io.Reader) (audio.Reader, error) {
+	switch f.Container {
+	case audio.ContainerWAV:
+		return audio.NewWavReader(r)
+	case "", audio.ContainerRaw:
+		rate, channels := f.SampleRate, f.Channels
+		if rate == 0 {
+			rate = SampleRate
+		}
+		if channels == 0 {
+			channels = 1
+		}
+		return audio.NewPCMReader(r, f.Encoding, rate, channels)
+	}
+	return nil, fmt.Errorf("%w: 封装格式 %q", audio.ErrUnsupportedFormat, f.Container)
+}
+
+// Chunk 音频片段
+type Chunk struct {
+	Offset  time.Duration // 片段在整个音频中的起始时间
+	Samples []float32     // 16KHz 单声道样本
+}
+
+// ChunkOptions 音频切分参数
+type ChunkOptions struct {
+	MaxDuration time.Duration // 每个片段的最大时长
+	MinDuration time.Duration // 大于 0 时在 [MinDuration, MaxDuration] 中选择能量最低 (静音) 的位置切分，否则按 MaxDuration 固定切分
+
+	// Splitter (可选) 语音段切分器，例如 *vad.Engine，设置后在 MaxDuration 窗口内最后一个结束的语音段之后的静音处切分，
+	// 忽略 MinDuration，只有单个语音段超过 MaxDuration 时才按上述规则切分
+	Splitter Splitter
+}
+
+// ChunkReader 从音频流增量读取样本，转换为 16KHz 单声道后切分为片段
+//
+// 内存占用只与 MaxDuration 成正比，与音频总时长无关，适用于数小时的录音
+type ChunkReader struct {
+	src       audio.Reader
+	resampler *audio.Resampler // 采样率为 16KHz 时为 nil
+	maxLen    int
+	minLen    int
+	splitter  Splitter
+
+	readBuf []float32
+	buf     []float32 // 尚未输出的样本
+	offset  int64     // buf[0] 在整个音频中的样本序号
+	eof     bool
+}
+
+// NewChunkReader 创建片段读取器
+//
+// # Params:
+//
+//	r: 音频流
+//	format: 音频格式
+//	opt: 切分参数
+//
+// # Examples:
+//
+//	chunks, err := asr.NewChunkReader(file, asr.FormatWAV, asr.ChunkOptions{MaxDuration: 30 * time.Second})
+//	for {
+//		chunk, err := chunks.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		// ...
+//	}
+func NewChunkReader(r io.Reader, format AudioFormat, opt ChunkOptions) (*ChunkReader, error) {
+	if opt.MaxDuration <= 0 {
+		return nil, fmt.Errorf("片段最大时长必须大于 0")
+	}
+	src, err := format.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	c := &ChunkReader{
+		src:      src,
+		maxLen:   int(opt.MaxDuration.Seconds() * SampleRate),
+		minLen:   min(int(opt.MinDuration.Seconds()*SampleRate), int(opt.MaxDuration.Seconds()*SampleRate)),
+		splitter: opt.Splitter,
+		readBuf:  make([]float32, 4096*src.Channels()),
+	}
+	if src.SampleRate() != SampleRate {
+		if c.resampler, err = audio.NewResampler(src.SampleRate(), SampleRate, audio.QualityMedium); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Next 返回下一个片段，音频读完时返回 io.EOF
+func (c *ChunkReader) Next() (Chunk, error) {
+	for !c.eof && len(c.buf) < c.maxLen {
+		if err := c.fill(); err != nil {
+			return Chunk{}, err
+		}
+	}
+	if len(c.buf) == 0 {
+		return Chunk{}, io.EOF
+	}
+
+	cut := len(c.buf)
+	if cut > c.maxLen {
+		var err error
+		if cut, err = c.splitPoint(); err != nil {
+			return Chunk{}, err
+		}
+	}
+	chunk := Chunk{
+		Offset:  time.Duration(c.offset) * time.Second / SampleRate,
+		Samples: append([]float32(nil), c.buf[:cut]...),
+	}
+	n := copy(c.buf, c.buf[cut:])
+	c.buf = c.buf[:n]
+	c.offset += int64(cut)
+	return chunk, nil
+}
+
+// fill 读取一批样本，转换为 16KHz 单声道后追加到缓冲
+func (c *ChunkReader) fill() error {
+	n, err := c.src.Read(c.readBuf)
+	mono := audio.Downmix(c.readBuf[:n], c.src.Channels())
+	if c.resampler != nil {
+		mono = c.resampler.Process(mono)
+	}
+	c.buf = append(c.buf, mono...)
+
+	if errors.Is(err, io.EOF) {
+		if c.resampler != nil {
+			c.buf = append(c.buf, c.resampler.Flush()...)
+		}
+		c.eof = true
+		return nil
+	}
+	return err
+}
+
+// splitPoint 选择切分位置: 优先使用语音段之间的静音，固定切分时为 maxLen，静音切分时为 [minLen, maxLen] 中能量最低的 10ms 帧的中心
+func (c *ChunkReader) splitPoint() (int, error) {
+	if c.splitter != nil {
+		cut, err := c.speechSplitPoint()
+		if err != nil || cut > 0 {
+			return cut, err
+		}
+	}
+	if c.minLen <= 0 {
+		return c.maxLen, nil
+	}
+	lo, hi := c.minLen, c.maxLen
+	// 音频即将结束时保证最后一个片段不短于 minLen
+	if c.eof {
+		hi = min(hi, len(c.buf)-c.minLen)
+		if hi < lo {
+			return len(c.buf) / 2, nil
+		}
+	}
+	return quietest(c.buf, lo, hi), nil
+}
+
+// speechSplitPoint 使用 Splitter 检测窗口内的语音段，返回最后一个结束的语音段与下一个语音段之间静音的中点
+//
+// 窗口内没有语音时返回 maxLen，没有合适的切分位置时返回 0
+func (c *ChunkReader) speechSplitPoint() (int, error) {
+	spans, err := c.splitter.Split(c.buf[:c.maxLen])
+	if err != nil {
+		return 0, fmt.Errorf("切分语音段失败: %w", err)
+	}
+	if len(spans) == 0 {
+		return c.maxLen, nil
+	}
+	slices.SortFunc(spans, func(a, b Span) int { return cmp.Compare(a.Start, b.Start) })
+	toSample := func(d time.Duration) int {
+		return min(max(int(d.Seconds()*SampleRate), 0), c.maxLen)
+	}
+	next := c.maxLen
+	for i := len(spans) - 1; i >= 0; i-- {
+		if end := toSample(spans[i].End); end < next {
+			return max((end+next)/2, 1), nil
+		}
+		next = toSample(spans[i].Start)
+	}
+	return 0, nil
+}
+
+// quietest 返回 samples[lo:hi] 中能量最低的 10ms 帧的中心位置
+func quietest(samples []float32, lo, hi int) int {
+	const frame = SampleRate / 100
+	best, bestEnergy := hi, float32(-1)
+	for start := lo; start+frame <= hi; start += frame {
+		var energy float32
+		for _, v := range samples[start : start+frame] {
+			energy += v * v
+		}
+		if bestEnergy < 0 || energy < bestEnergy {
+			best, bestEnergy = start+frame/2, energy
+		}
+	}
+	return best
+}
+
+// ErrEmptyStream 音频流中没有样本
+var ErrEmptyStream = errors.New("asr: empty audio stream")
+
+// TranscribeChunks 依次识别片段并拼接结果，供各引擎实现 TranscribeReader
+//
+// 各片段的 Words 与 Segments 偏移到整段音频，读取片段的错误原样返回，音频流为空时返回 ErrEmptyStream
+func TranscribeChunks(ctx context.Context, chunks *ChunkReader, transcribe func(ctx context.Context, samples []float32) (*Result, error)) (*Result, error) {
+	var texts []string
+	var words []Word
+	var segments []Segment
+	var language string
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunk, err := chunks.Next()
+		if errors.Is(err, io.EOF) {
+			if n == 0 {
+				return nil, ErrEmptyStream
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result, err := transcribe(ctx, chunk.Samples)
+		if err != nil {
+			return nil, fmt.Errorf("识别 %v 处的片段失败: %w", chunk.Offset, err)
+		}
+		if result == nil {
+			return nil, fmt.Errorf("识别 %v 处的片段没有返回结果", chunk.Offset)
+		}
+		texts = append(texts, result.Text)
+		for _, w := range result.Words {
+			words = append(words, Word{Text: w.Text, Start: chunk.Offset + w.Start, End: chunk.Offset + w.End})
+		}
+		for _, seg := range result.Segments {
+			seg.Start, seg.End = chunk.Offset+seg.Start, chunk.Offset+seg.End
+			segments = append(segments, seg)
+		}
+		if language == "" {
+			language = result.Language
+		}
+	}
+	return &Result{Text: JoinText(texts), Language: language, Words: words, Segments: segments}, nil
+}
+
+// JoinText 拼接分段识别的文本，两侧均不是中日韩文字或全角标点时插入空格
+func JoinText(parts []string) string {
+	var sb strings.Builder
+	var last rune
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		first, _ := utf8.DecodeRuneInString(p)
+		if sb.Len() > 0 && !isCJK(last) && !isCJK(first) && !unicode.IsPunct(first) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(p)
+		last, _ = utf8.DecodeLastRuneInString(p)
+	}
+	return sb.String()
+}
+
+// isCJK 判断是否为中日韩文字或全角标点，这类字符之间不需要空格
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		r >= 0x3000 && r <= 0x303F || r >= 0xFF00 && r <= 0xFFEF
+}
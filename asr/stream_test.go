@@ -0,0 +1,168 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+// pcm16 生成 16 位裸 PCM: 交替的 1 秒 440Hz 正弦波与 0.5 秒静音
+func pcm16(rate int, seconds int) []byte {
+	var b bytes.Buffer
+	for i := 0; i < rate*seconds; i++ {
+		var v float64
+		if i%(rate*3/2) < rate {
+			v = 0.5 * math.Sin(2*math.Pi*440*float64(i)/float64(rate))
+		}
+		_ = binary.Write(&b, binary.LittleEndian, int16(v*32767))
+	}
+	return b.Bytes()
+}
+
+func TestChunkReader(t *testing.T) {
+	data := pcm16(8000, 10)
+
+	// 固定窗口
+	chunks, err := NewChunkReader(bytes.NewReader(data), AudioFormat{SampleRate: 8000}, ChunkOptions{MaxDuration: 3 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	var offsets []time.Duration
+	for {
+		chunk, err := chunks.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunk.Samples) > 3*SampleRate {
+			t.Fatalf("chunk too long: %d", len(chunk.Samples))
+		}
+		offsets = append(offsets, chunk.Offset)
+		total += len(chunk.Samples)
+	}
+	if total != 10*SampleRate || len(offsets) != 4 || offsets[1] != 3*time.Second {
+		t.Fatalf("total %d, offsets %v", total, offsets)
+	}
+
+	// 静音切分: 切分点应落在静音段 (每 1.5 秒中的后 0.5 秒)
+	chunks, _ = NewChunkReader(bytes.NewReader(data), AudioFormat{SampleRate: 8000}, ChunkOptions{MinDuration: 2 * time.Second, MaxDuration: 4 * time.Second})
+	total = 0
+	for {
+		chunk, err := chunks.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(chunk.Samples); n < 2*SampleRate && total+n < 10*SampleRate {
+			t.Fatalf("chunk too short: %d", n)
+		}
+		total += len(chunk.Samples)
+		if end := float64(total) / SampleRate; total < 10*SampleRate && math.Mod(end, 1.5) < 1 {
+			t.Fatalf("split at %.2fs is not in silence", end)
+		}
+	}
+	if total != 10*SampleRate {
+		t.Fatalf("total %d", total)
+	}
+}
+
+func TestTranscribeChunks(t *testing.T) {
+	chunks, _ := NewChunkReader(bytes.NewReader(pcm16(16000, 5)), FormatPCM16k, ChunkOptions{MaxDuration: 2 * time.Second})
+	var calls int
+	result, err := TranscribeChunks(context.Background(), chunks, func(_ context.Context, samples []float32) (*Result, error) {
+		calls++
+		text := []string{"hello", "world.", "你好"}[calls-1]
+		return &Result{Text: text, Language: "en", Segments: []Segment{{Start: 100 * time.Millisecond, End: time.Second, Text: text}}}, nil
+	})
+	if err != nil || result.Text != "hello world.你好" || result.Language != "en" {
+		t.Fatalf("got %+v, %v", result, err)
+	}
+	// 片段的 Segments 偏移到整段音频
+	if len(result.Segments) != 3 || result.Segments[2].Start != 4100*time.Millisecond || result.Segments[2].End != 5*time.Second {
+		t.Fatalf("segments: %+v", result.Segments)
+	}
+
+	chunks, _ = NewChunkReader(bytes.NewReader(pcm16(16000, 1)), FormatPCM16k, ChunkOptions{MaxDuration: 2 * time.Second})
+	if _, err := TranscribeChunks(context.Background(), chunks, func(context.Context, []float32) (*Result, error) { return nil, nil }); err == nil {
+		t.Fatal("expected nil result error")
+	}
+
+	chunks, _ = NewChunkReader(bytes.NewReader(nil), FormatPCM16k, ChunkOptions{MaxDuration: time.Second})
+	if _, err := TranscribeChunks(context.Background(), chunks, nil); !errors.Is(err, ErrEmptyStream) {
+		t.Fatalf("empty stream: %v", err)
+	}
+}
+
+func TestJoinText(t *testing.T) {
+	cases := []struct {
+		parts []string
+		want  string
+	}{
+		{[]string{"今天天气", "很好。"}, "今天天气很好。"},
+		{[]string{" Hello", "world "}, "Hello world"},
+		{[]string{"Hello.", "明天见", "OK"}, "Hello.明天见OK"},
+		{[]string{"one", "", ", two"}, "one, two"},
+	}
+	for _, c := range cases {
+		if got := JoinText(c.parts); got != c.want {
+			t.Fatalf("%q: got %q, want %q", c.parts, got, c.want)
+		}
+	}
+}
+
+// energySplitter 将幅度超过 0.01 的 10ms 帧视为语音
+type energySplitter struct{}
+
+func (energySplitter) Split(samples []float32) ([]Span, error) {
+	const frame = SampleRate / 100
+	var spans []Span
+	for start := 0; start+frame <= len(samples); start += frame {
+		voiced := false
+		for _, v := range samples[start : start+frame] {
+			voiced = voiced || v > 0.01 || v < -0.01
+		}
+		at := time.Duration(start) * time.Second / SampleRate
+		switch {
+		case voiced && len(spans) > 0 && spans[len(spans)-1].End == at:
+			spans[len(spans)-1].End = at + 10*time.Millisecond
+		case voiced:
+			spans = append(spans, Span{Start: at, End: at + 10*time.Millisecond})
+		}
+	}
+	return spans, nil
+}
+
+func TestChunkReaderSplitter(t *testing.T) {
+	chunks, err := NewChunkReader(bytes.NewReader(pcm16(SampleRate, 10)), FormatPCM16k, ChunkOptions{MaxDuration: 4 * time.Second, Splitter: energySplitter{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lengths []int
+	for {
+		chunk, err := chunks.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths = append(lengths, len(chunk.Samples))
+	}
+	// 每个 4 秒窗口内最后的语音到窗口末尾仍未结束，在前一个语音段之后的静音中点切分:
+	// 2.75s, 5.75s (2.75s + 3s), 8.75s
+	want := []int{44000, 48000, 48000, 20000}
+	if !slices.Equal(lengths, want) {
+		t.Fatalf("chunks %v, want %v", lengths, want)
+	}
+}
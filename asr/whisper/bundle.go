@@ -0,0 +1,59 @@
+package whisper
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"github.com/getcharzp/go-speech"
+)
+
+// LoadBundle 从模型包目录加载 Whisper 引擎
+//
+// 模型包需包含 manifest.json，文件角色: encoder, decoder, tokens, added_tokens，
+// 超参数: model_layers, (可选) num_heads, max_tokens
+//
+// # Params:
+//
+//	dir: 模型包目录
+//	publicKey: (可选) Ed25519 公钥，非空时校验清单签名
+func LoadBundle(dir string, publicKey ed25519.PublicKey) (*Engine, error) {
+	cfg, err := ConfigFromBundle(dir, DefaultConfig(), publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(cfg)
+}
+
+// ConfigFromBundle 校验模型包并将其中的文件路径与超参数填入配置
+//
+// # Params:
+//
+//	dir: 模型包目录
+//	cfg: 基础配置，ONNX 相关参数保持不变
+//	publicKey: (可选) Ed25519 公钥，非空时校验清单签名
+func ConfigFromBundle(dir string, cfg Config, publicKey ed25519.PublicKey) (Config, error) {
+	m, err := speech.OpenBundle(dir, "whisper", publicKey)
+	if err != nil {
+		return cfg, err
+	}
+	if err := m.Require("encoder", "decoder", "tokens", "added_tokens"); err != nil {
+		return cfg, err
+	}
+
+	cfg.EncoderModelPath = m.Path("encoder")
+	cfg.DecoderModelPath = m.Path("decoder")
+	cfg.TokensPath = m.Path("tokens")
+	cfg.AddedTokensPath = m.Path("added_tokens")
+
+	layers, ok := m.Int("model_layers")
+	if !ok {
+		return cfg, fmt.Errorf("模型包缺少超参数: model_layers")
+	}
+	cfg.ModelLayers = layers
+	if heads, ok := m.Int("num_heads"); ok {
+		cfg.NumHeads = heads
+	}
+	if maxTokens, ok := m.Int("max_tokens"); ok {
+		cfg.MaxTokens = maxTokens
+	}
+	return cfg, nil
+}
@@ -2,9 +2,18 @@ package whisper
 
 import (
 	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"io"
+	"log/slog"
+	"time"
 )
 
+// engineName 引擎名称，用于注册、日志与错误
+const engineName = "whisper"
+
 const (
+	// LangAuto 转录前自动检测语言
+	LangAuto = "auto"
 	// LangEn 英语
 	LangEn = "en"
 	// LangZh 中文
@@ -212,21 +221,37 @@ const (
 	TaskTranslate = "translate"
 )
 
+const (
+	// chunkMinDuration, chunkMaxDuration TranscribeReader 的窗口时长，在窗口末尾 6 秒内能量最低的位置切分，避免截断单词
+	chunkMinDuration = 24 * time.Second
+	chunkMaxDuration = 30 * time.Second
+)
+
 // Config Whisper 模型的配置参数
 type Config struct {
 	// 必填参数
-	OnnxRuntimeLibPath string
-	DecoderModelPath   string
-	EncoderModelPath   string
-	TokensPath         string // vocab.json 文件路径
-	AddedTokensPath    string // added_tokens.json 文件路径
-	ModelLayers        int
-	MaxTokens          int
+	OnnxRuntimeLibPath string `json:"onnx_runtime_lib_path"`
+	DecoderModelPath   string `json:"decoder_model_path"`
+	EncoderModelPath   string `json:"encoder_model_path"`
+	TokensPath         string `json:"tokens_path"`       // vocab.json 文件路径
+	AddedTokensPath    string `json:"added_tokens_path"` // added_tokens.json 文件路径
+	ModelLayers        int    `json:"model_layers"`
+	MaxTokens          int    `json:"max_tokens"`
+	NumHeads           int    `json:"num_heads"` // (可选) 注意力头数, 默认根据 ModelLayers 推断
 
 	// 可选参数
-	UseCuda           bool // (可选) 是否启用 CUDA
-	NumThreads        int  // (可选) ONNX 线程数, 默认由CPU核心数决定
-	EnableCpuMemArena bool // (可选) 是否启用内存池
+	UseCuda                bool            `json:"use_cuda"`                 // (可选) 是否启用 CUDA
+	NumThreads             int             `json:"num_threads"`              // (可选) ONNX 线程数, 默认由CPU核心数决定
+	EnableCpuMemArena      bool            `json:"enable_cpu_mem_arena"`     // (可选) 是否启用内存池
+	GraphOptimizationLevel string          `json:"graph_optimization_level"` // (可选) 图优化级别: disable, basic, extended, all
+	ExecutionMode          string          `json:"execution_mode"`           // (可选) 执行模式: sequential, parallel
+	InterOpNumThreads      int             `json:"inter_op_num_threads"`     // (可选) 算子间并行线程数，仅 parallel 模式生效
+	DisableMemPattern      bool            `json:"disable_mem_pattern"`      // (可选) 禁用内存模式优化
+	OptimizedModelDir      string          `json:"optimized_model_dir"`      // (可选) 优化后模型的缓存目录，用于缩短冷启动时间
+	Runtime                *speech.Runtime `json:"-"`                        // (可选) 共享的 ONNX 运行时，设置后忽略 OnnxRuntimeLibPath
+	Logger                 *slog.Logger    `json:"-"`                        // (可选) 日志记录器，默认使用 slog.Default()
+	Observer               speech.Observer `json:"-"`                        // (可选) 推理观测者，接收各阶段耗时、音频时长、Token 数与实时率
+	Splitter               asr.Splitter    `json:"-"`                        // (可选) TranscribeReader 的语音段切分器，例如 *vad.Engine，为空时在能量最低处切分
 }
 
 // DefaultConfig 默认配置
@@ -242,8 +267,31 @@ func DefaultConfig() Config {
 	}
 }
 
-// TranscribeOption 转录配置参数
-type TranscribeOption struct {
-	Language string // 被转录的语言，例如："zh", "en", "ja"
-	Task     string // 任务类型，例如："transcribe", "translate"
+func init() {
+	speech.Register(engineName, func(spec speech.EngineSpec) (io.Closer, error) {
+		cfg := DefaultConfig()
+		if spec.Bundle != "" {
+			var err error
+			if cfg, err = ConfigFromBundle(spec.Bundle, cfg, spec.PublicKey); err != nil {
+				return nil, err
+			}
+		}
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		engine, err := NewEngine(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	})
 }
+
+// TranscribeOption 转录配置参数，与 asr.TranscribeOption 等价
+type TranscribeOption = asr.TranscribeOption
+
+// Segment 带时间范围的转录片段，与 asr.Segment 等价
+type Segment = asr.Segment
+
+// LongOption 长音频转录配置参数，与 asr.LongOption 等价
+type LongOption = asr.LongOption
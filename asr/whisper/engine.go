@@ -1,18 +1,36 @@
 package whisper
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"github.com/getcharzp/go-speech/audio"
 	ort "github.com/getcharzp/onnxruntime_purego"
 	"github.com/up-zero/gotool/convertutil"
+	"io"
+	"log/slog"
 	"math"
 	"os"
+	"slices"
 	"strings"
 	"unicode/utf8"
 )
 
+const (
+	// vocabSize 多语言模型的词表大小
+	vocabSize = 51865
+	// nTextCtx Decoder 的上下文长度，提示与生成的 Token 总数不能超过该值
+	nTextCtx = 448
+)
+
 // Engine 封装了 Whisper 的 ONNX 运行时和相关资源
+//
+// Engine 不是并发安全的，并发推理请使用 speech.Pool 持有多个实例
 type Engine struct {
+	onnx        *speech.OnnxConfig
 	encSession  *ort.Session
 	decSession  *ort.Session
 	tokenMap    map[int]string
@@ -24,13 +42,27 @@ type Engine struct {
 	headDim     int
 
 	sot, eot, noTime int
+	sop, noSpeech    int            // <|startofprev|>, <|nospeech|>
+	timeBegin        int            // <|0.00|>，之后的 Token 均为时间戳
+	languages        map[int]string // 语言 Token -> 语言代码
 
 	decInputNames  []string
 	decOutputNames []string
+
+	splitter asr.Splitter // TranscribeReader 使用的语音段切分器
+
+	logger   *slog.Logger
+	observer speech.Observer
 }
 
+var _ asr.Recognizer = (*Engine)(nil)
+
 // NewEngine 初始化 Whisper 引擎
-func NewEngine(cfg Config) (*Engine, error) {
+//
+// 失败时返回的错误满足 errors.Is(err, speech.ErrModelLoad)
+func NewEngine(cfg Config) (_ *Engine, err error) {
+	defer func() { err = speech.WrapError(speech.ErrModelLoad, engineName, err) }()
+
 	oc := new(speech.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 
@@ -39,11 +71,19 @@ func NewEngine(cfg Config) (*Engine, error) {
 		return nil, err
 	}
 
+	engine := &Engine{onnx: oc, logger: speech.EngineLogger(cfg.Logger, engineName), observer: cfg.Observer, splitter: cfg.Splitter}
+
 	// 创建 Encoder 会话
-	encSession, err := oc.OnnxEngine.NewSession(cfg.EncoderModelPath, oc.SessionOptions)
+	encSession, err := oc.NewSession(cfg.EncoderModelPath)
 	if err != nil {
+		engine.Destroy()
 		return nil, fmt.Errorf("创建 Encoder 会话失败: %w", err)
 	}
+	engine.encSession = encSession
+	if err := speech.CheckSession(encSession, []string{"input_features"}, []string{"last_hidden_state"}); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("Encoder %w", err)
+	}
 
 	// 组装 Decoder 的输入输出
 	decInputNames := []string{"input_ids", "encoder_hidden_states", "use_cache_branch"}
@@ -62,126 +102,302 @@ func NewEngine(cfg Config) (*Engine, error) {
 	}
 
 	// 创建 Decoder 会话
-	decSession, err := oc.OnnxEngine.NewSession(cfg.DecoderModelPath, oc.SessionOptions)
+	decSession, err := oc.NewSession(cfg.DecoderModelPath)
 	if err != nil {
-		encSession.Destroy()
+		engine.Destroy()
 		return nil, fmt.Errorf("创建 Decoder 会话失败: %w", err)
 	}
+	engine.decSession = decSession
+	if err := speech.CheckSession(decSession, decInputNames, decOutputNames); err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("Decoder %w (请检查 ModelLayers)", err)
+	}
 
 	// 加载 Token
 	tokenMap, addTokenMap, err := loadTokens(cfg.TokensPath, cfg.AddedTokensPath)
 	if err != nil {
+		engine.Destroy()
 		return nil, err
 	}
 
-	return &Engine{
-		encSession:     encSession,
-		decSession:     decSession,
-		tokenMap:       tokenMap,
-		addTokenMap:    addTokenMap,
-		modelLayers:    cfg.ModelLayers,
-		maxTokens:      cfg.MaxTokens,
-		numHeads:       calculateNumHeads(cfg.ModelLayers),
-		headDim:        64,
-		decInputNames:  decInputNames,
-		decOutputNames: decOutputNames,
-		sot:            50258,
-		eot:            50257,
-		noTime:         50363,
-	}, nil
+	numHeads := cfg.NumHeads
+	if numHeads <= 0 {
+		numHeads = calculateNumHeads(cfg.ModelLayers)
+	}
+
+	engine.tokenMap = tokenMap
+	engine.addTokenMap = addTokenMap
+	engine.modelLayers = cfg.ModelLayers
+	engine.maxTokens = cfg.MaxTokens
+	engine.numHeads = numHeads
+	engine.headDim = 64
+	engine.decInputNames = decInputNames
+	engine.decOutputNames = decOutputNames
+	engine.sot = 50258
+	engine.eot = 50257
+	engine.noTime = 50363
+	engine.sop = cmp.Or(addTokenMap["<|startofprev|>"], 50361)
+	engine.noSpeech = cmp.Or(addTokenMap["<|nospeech|>"], addTokenMap["<|nocaptions|>"], 50362)
+	engine.timeBegin = cmp.Or(addTokenMap["<|0.00|>"], engine.noTime+1)
+
+	// <|startoftranscript|> 与 <|translate|> 之间的 Token 为语言
+	translate := cmp.Or(addTokenMap["<|translate|>"], 50358)
+	engine.languages = make(map[int]string)
+	for token, id := range addTokenMap {
+		if id > engine.sot && id < translate && strings.HasPrefix(token, "<|") && strings.HasSuffix(token, "|>") {
+			engine.languages[id] = strings.TrimSuffix(strings.TrimPrefix(token, "<|"), "|>")
+		}
+	}
+	return engine, nil
 }
 
-// TranscribeFile 读取并转录 WAV 文件
+// TranscribeFile 读取并转录音频文件 (WAV、FLAC、MP3)
 //
 // # Params:
 //
 //	wavPath: 音频文件路径
 //	opt: 转录可选参数
-func (e *Engine) TranscribeFile(wavPath string, opt ...TranscribeOption) (string, error) {
+func (e *Engine) TranscribeFile(wavPath string, opt ...TranscribeOption) (*asr.Result, error) {
 	wavBytes, err := os.ReadFile(wavPath)
 	if err != nil {
-		return "", fmt.Errorf("无法读取文件: %w", err)
+		return nil, fmt.Errorf("无法读取文件: %w", err)
 	}
 	return e.TranscribeBytes(wavBytes, opt...)
 }
 
-// TranscribeBytes 转录 WAV 字节流
+// TranscribeBytes 转录音频字节流 (WAV、FLAC、MP3)
 //
 // # Params:
 //
 //	wavBytes: 音频文件字节流
 //	opt: 转录可选参数
-func (e *Engine) TranscribeBytes(wavBytes []byte, opt ...TranscribeOption) (string, error) {
-	samples, err := parseWavBytes(wavBytes)
+func (e *Engine) TranscribeBytes(wavBytes []byte, opt ...TranscribeOption) (*asr.Result, error) {
+	if len(opt) > 0 && opt[0].SplitChannels {
+		return e.transcribeChannels(wavBytes, opt...)
+	}
+	samples, err := audio.DecodeMono(wavBytes, sampleRate)
 	if err != nil {
-		return "", fmt.Errorf("无法将 PCM 数据转换为 float32: %v", err)
+		return nil, &speech.Error{Kind: speech.ErrInvalidAudio, Engine: engineName, Message: "无法解码音频数据", Err: err}
 	}
 	return e.Transcribe(samples, opt...)
 }
 
+// transcribeChannels 对每个声道分别识别，合并为按时间排序的对话
+func (e *Engine) transcribeChannels(wavBytes []byte, opt ...TranscribeOption) (_ *asr.Result, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInvalidAudio, engineName, err) }()
+	buf, err := audio.Decode(wavBytes)
+	if err != nil {
+		return nil, fmt.Errorf("无法解码音频数据: %w", err)
+	}
+	result, err := asr.TranscribeChannels(context.Background(), buf, opt[0], chunkMaxDuration, func(ctx context.Context, samples []float32) (*asr.Result, error) {
+		return e.TranscribeContext(ctx, samples, opt...)
+	})
+	if errors.Is(err, asr.ErrEmptyStream) {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频数据为空", "")
+	}
+	return result, err
+}
+
+// TranscribeReader 从音频流增量读取并识别，按 30 秒窗口切分后逐段转录
+//
+// 配置了 Splitter 时在语音段之间的静音处切分窗口，否则在窗口末尾 6 秒内能量最低的位置切分
+//
+// # Params:
+//
+//	r: 音频流，例如文件或网络连接
+//	format: 音频格式，例如 asr.FormatWAV、asr.FormatPCM16k
+//	opt: 转录可选参数
+func (e *Engine) TranscribeReader(r io.Reader, format asr.AudioFormat, opt ...TranscribeOption) (*asr.Result, error) {
+	return e.TranscribeReaderContext(context.Background(), r, format, opt...)
+}
+
+// TranscribeReaderContext 从音频流增量读取并识别，ctx 结束时在片段之间或片段内中止
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	r: 音频流
+//	format: 音频格式
+//	opt: 转录可选参数
+func (e *Engine) TranscribeReaderContext(ctx context.Context, r io.Reader, format asr.AudioFormat, opt ...TranscribeOption) (_ *asr.Result, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInvalidAudio, engineName, err) }()
+	chunks, err := asr.NewChunkReader(r, format, asr.ChunkOptions{MinDuration: chunkMinDuration, MaxDuration: chunkMaxDuration, Splitter: e.splitter})
+	if err != nil {
+		return nil, fmt.Errorf("无法读取音频流: %w", err)
+	}
+	result, err := asr.TranscribeChunks(ctx, chunks, func(ctx context.Context, samples []float32) (*asr.Result, error) {
+		return e.TranscribeContext(ctx, samples, opt...)
+	})
+	if errors.Is(err, asr.ErrEmptyStream) {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频流为空", "")
+	}
+	return result, err
+}
+
 // Transcribe 对 float32 音频样本数据进行转录
 //
 // # Params:
 //
 //	samples: 采样率 16KHz 的单声道音频数据，范围 [-1, 1]
 //	opt: 转录可选参数
-func (e *Engine) Transcribe(samples []float32, opt ...TranscribeOption) (string, error) {
-	// 特征提取
-	features, err := e.extractFeatures(samples)
-	if err != nil {
-		return "", err
+func (e *Engine) Transcribe(samples []float32, opt ...TranscribeOption) (*asr.Result, error) {
+	return e.TranscribeContext(context.Background(), samples, opt...)
+}
+
+// TranscribeContext 对 float32 音频样本数据进行转录，ctx 结束时在特征帧之间或解码步之间中止
+//
+// 超过 30 秒的音频使用 Whisper 的顺序长音频算法逐窗口转录，结果的 Segments 覆盖整段音频
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	samples: 采样率 16KHz 的单声道音频数据，范围 [-1, 1]
+//	opt: 转录可选参数
+func (e *Engine) TranscribeContext(ctx context.Context, samples []float32, opt ...TranscribeOption) (_ *asr.Result, err error) {
+	rec := speech.StartCall(e.observer, engineName, speech.OpTranscribe)
+	defer func() { rec.Finish(ctx, err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	if len(samples) == 0 {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频数据为空", "")
 	}
+	rec.SetAudio(len(samples), sampleRate)
 
-	encIn, _ := ort.NewTensor([]int64{1, 80, 3000}, features)
-	defer encIn.Destroy()
+	// 未指定的语言与任务使用默认值，仅设置 SplitChannels 等参数时不必填写
+	language, task := LangZh, TaskTranscribe
+	if len(opt) > 0 {
+		language, task = cmp.Or(opt[0].Language, LangZh), cmp.Or(opt[0].Task, TaskTranscribe)
+	}
 
-	inputValues := map[string]*ort.Value{
-		"input_features": encIn,
+	// 长音频在检测语言时只编码第一个窗口，逐窗口转录时复用该结果
+	var hiddenState *ort.Value
+	if len(samples) <= maxSmpl || language == LangAuto {
+		if hiddenState, err = e.encode(ctx, rec, samples); err != nil {
+			return nil, err
+		}
+		defer hiddenState.Destroy()
+	}
+	if language == LangAuto {
+		probs, err := e.detectLanguage(ctx, hiddenState)
+		if err != nil {
+			return nil, err
+		}
+		language = probs[0].Language
+		e.logger.Debug("检测到语言", "language", language, "prob", probs[0].Prob)
 	}
 
-	// Encoder 推理
-	outputValues, err := e.encSession.Run(inputValues)
+	sot, err := e.sotSequence(language, task)
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) > maxSmpl {
+		return e.transcribeSequential(ctx, rec, samples, hiddenState, sot, language)
+	}
+
+	timestamps := len(opt) > 0 && opt[0].WithTimestamps
+	if !timestamps {
+		sot = append(sot, int64(e.noTime))
+	}
+	out, err := e.runMergedDecoder(ctx, rec, hiddenState, sot, timestamps)
 	if err != nil {
-		return "", fmt.Errorf("编码推理失败: %w", err)
+		return nil, err
+	}
+	rec.Stage("decoder")
+
+	result := &asr.Result{Language: language}
+	if !timestamps {
+		result.Text = e.decode(out.tokens)
+		return result, nil
 	}
-	outputValue := outputValues["last_hidden_state"]
-	defer outputValue.Destroy()
 
-	return e.runMergedDecoder(outputValue, opt...)
+	parts, _ := splitTimestamps(out.tokens, e.timeBegin, len(samples))
+	// 单个窗口不再继续解码，未完整结束的 Token 也作为最后一个片段
+	covered := 0
+	for _, p := range parts {
+		covered += len(p.tokens)
+	}
+	if rest := out.tokens[covered:]; len(rest) > 0 {
+		prevEnd := parts[len(parts)-1].end
+		parts = append(parts, timedTokens{start: prevEnd, end: len(samples), tokens: rest})
+	}
+	result.Segments = e.segments(parts, 0)
+	result.Text = segmentsText(result.Segments)
+	return result, nil
 }
 
-// runMergedDecoder Merge Decoder 推理
-func (e *Engine) runMergedDecoder(encHiddenState *ort.Value, opt ...TranscribeOption) (string, error) {
-	// prompt: [<|startoftranscript|>, <|language|>, <|task|>, <|notimestamps|>]
-	prompt := []int64{int64(e.sot)}
-	if len(opt) == 0 {
-		prompt = append(prompt, int64(50260)) // zh
-		prompt = append(prompt, int64(50359)) // transcribe
-	} else {
-		if lang, ok := e.addTokenMap[fmt.Sprintf("<|%s|>", opt[0].Language)]; ok {
-			prompt = append(prompt, int64(lang))
-		} else {
-			return "", fmt.Errorf("未知语言: %s", opt[0].Language)
-		}
+// sotSequence 返回 [<|startoftranscript|>, <|language|>, <|task|>]
+func (e *Engine) sotSequence(language, task string) ([]int64, error) {
+	langID, ok := e.addTokenMap[fmt.Sprintf("<|%s|>", language)]
+	if !ok {
+		return nil, speech.NewError(speech.ErrUnsupportedLanguage, engineName, "未知语言", language)
+	}
+	taskID, ok := e.addTokenMap[fmt.Sprintf("<|%s|>", task)]
+	if !ok {
+		return nil, speech.NewError(speech.ErrUnsupportedTask, engineName, "未知任务", task)
+	}
+	return []int64{int64(e.sot), int64(langID), int64(taskID)}, nil
+}
 
-		if task, ok := e.addTokenMap[fmt.Sprintf("<|%s|>", opt[0].Task)]; ok {
-			prompt = append(prompt, int64(task))
-		} else {
-			return "", fmt.Errorf("未知任务: %s", opt[0].Task)
-		}
+// encode 提取至多 30 秒音频的特征并运行 Encoder，返回的 last_hidden_state 由调用方释放
+func (e *Engine) encode(ctx context.Context, rec *speech.Recorder, samples []float32) (*ort.Value, error) {
+	features, err := e.extractFeatures(ctx, samples)
+	if err != nil {
+		return nil, err
+	}
+	rec.Stage("feature")
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		prompt = append(prompt, int64(50260)) // zh
-		prompt = append(prompt, int64(50359)) // transcribe
+	encIn, err := ort.NewTensor([]int64{1, 80, 3000}, features)
+	if err != nil {
+		return nil, fmt.Errorf("创建 input_features tensor 失败: %w", err)
+	}
+	defer encIn.Destroy()
+
+	// Encoder 推理
+	outputValues, err := e.encSession.Run(map[string]*ort.Value{
+		"input_features": encIn,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("编码推理失败: %w", err)
 	}
-	prompt = append(prompt, int64(e.noTime))
+	rec.Stage("encoder")
+	return outputValues["last_hidden_state"], nil
+}
 
+// decoded 一个窗口的解码结果
+type decoded struct {
+	tokens       []int   // 生成的 Token，不含 <|endoftext|>
+	avgLogprob   float64 // 生成 Token 的平均对数概率
+	noSpeechProb float64 // <|startoftranscript|> 位置预测 <|nospeech|> 的概率
+}
+
+// runMergedDecoder Merge Decoder 推理，ctx 结束时在解码步之间中止并释放 KV Cache
+//
+// prompt: [<|startofprev|>, 前文..., <|startoftranscript|>, <|language|>, <|task|>, (<|notimestamps|>)]
+// timestamps: 是否生成时间戳 Token
+func (e *Engine) runMergedDecoder(ctx context.Context, rec *speech.Recorder, encHiddenState *ort.Value, prompt []int64, timestamps bool) (*decoded, error) {
 	pastTensors, err := e.createPastTensors()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	// 任意路径退出时释放 KV Cache
+	defer func() {
+		for _, t := range pastTensors {
+			t.Destroy()
+		}
+	}()
 
-	inputIdsTensor, _ := ort.NewTensor([]int64{1, int64(len(prompt))}, prompt)
-	useCacheTensor, _ := ort.NewTensor([]int64{1}, []bool{false})
+	inputIdsTensor, err := ort.NewTensor([]int64{1, int64(len(prompt))}, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("创建 input_ids tensor 失败: %w", err)
+	}
+	defer inputIdsTensor.Destroy()
+	useCacheTensor, err := ort.NewTensor([]int64{1}, []bool{false})
+	if err != nil {
+		return nil, fmt.Errorf("创建 use_cache_branch tensor 失败: %w", err)
+	}
+	defer useCacheTensor.Destroy()
 
 	prefillInputs := map[string]*ort.Value{
 		"input_ids":             inputIdsTensor,
@@ -194,25 +410,14 @@ func (e *Engine) runMergedDecoder(encHiddenState *ort.Value, opt ...TranscribeOp
 
 	outputs, err := e.decSession.Run(prefillInputs)
 	if err != nil {
-		inputIdsTensor.Destroy()
-		useCacheTensor.Destroy()
-		for _, t := range pastTensors {
-			t.Destroy()
-		}
-		return "", fmt.Errorf("预解码推理失败: %w", err)
+		return nil, fmt.Errorf("预解码推理失败: %w", err)
 	}
-	inputIdsTensor.Destroy()
-	useCacheTensor.Destroy()
+
+	// 预解码的空缓存不再需要，替换为输出的缓存
 	for _, t := range pastTensors {
 		t.Destroy()
 	}
-
-	logits := outputs["logits"]
-
-	nextTokenID := e.sampleTokenPrefill(logits)
-	logits.Destroy()
-
-	// 更新缓存
+	clear(pastTensors)
 	for name, t := range outputs {
 		if strings.Contains(name, "present") {
 			key := strings.ReplaceAll(name, "present", "past_key_values")
@@ -220,19 +425,41 @@ func (e *Engine) runMergedDecoder(encHiddenState *ort.Value, opt ...TranscribeOp
 		}
 	}
 
+	out := new(decoded)
+	logits := outputs["logits"]
+	if data, _ := ort.GetTensorData[float32](logits); len(data) >= len(prompt)*vocabSize {
+		if i := slices.Index(prompt, int64(e.sot)); i >= 0 {
+			out.noSpeechProb = math.Exp(logProb(data[i*vocabSize:(i+1)*vocabSize], e.noSpeech))
+		}
+	}
+	nextTokenID, logprob := e.sampleTokenPrefill(logits, timestamps)
+	logits.Destroy()
+
 	generatedTokens := make([]int, 0)
 	generatedTokens = append(generatedTokens, nextTokenID)
+	sumLogprob := logprob
 
 	loopInputs := make(map[string]*ort.Value, 3+len(pastTensors))
 
-	// 循环生成
-	for i := 0; i < e.maxTokens; i++ {
+	// 循环生成，提示与生成的 Token 总数不超过 Decoder 的上下文长度
+	maxTokens := min(e.maxTokens, nTextCtx-len(prompt))
+	for i := 0; i < maxTokens; i++ {
 		if nextTokenID == e.eot {
 			break
 		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
-		currInTensor, _ := ort.NewTensor([]int64{1, 1}, []int64{int64(nextTokenID)})
-		currCacheTensor, _ := ort.NewTensor([]int64{1}, []bool{true})
+		currInTensor, err := ort.NewTensor([]int64{1, 1}, []int64{int64(nextTokenID)})
+		if err != nil {
+			return nil, fmt.Errorf("创建 input_ids tensor 失败: %w", err)
+		}
+		currCacheTensor, err := ort.NewTensor([]int64{1}, []bool{true})
+		if err != nil {
+			currInTensor.Destroy()
+			return nil, fmt.Errorf("创建 use_cache_branch tensor 失败: %w", err)
+		}
 
 		loopInputs["input_ids"] = currInTensor
 		loopInputs["encoder_hidden_states"] = encHiddenState
@@ -245,7 +472,7 @@ func (e *Engine) runMergedDecoder(encHiddenState *ort.Value, opt ...TranscribeOp
 		currInTensor.Destroy()
 		currCacheTensor.Destroy()
 		if err != nil {
-			return "", fmt.Errorf("第 %d 步解码推理失败: %w", i, err)
+			return nil, fmt.Errorf("第 %d 步解码推理失败: %w", i, err)
 		}
 
 		newLogits := newOutputs["logits"]
@@ -271,18 +498,24 @@ func (e *Engine) runMergedDecoder(encHiddenState *ort.Value, opt ...TranscribeOp
 			}
 		}
 
-		nextTokenID = e.sampleToken(newLogits, generatedTokens)
+		nextTokenID, logprob = e.sampleToken(newLogits, generatedTokens, timestamps)
 		newLogits.Destroy()
 
 		generatedTokens = append(generatedTokens, nextTokenID)
+		sumLogprob += logprob
 	}
-
-	// 清除缓存张量
-	for _, t := range pastTensors {
-		t.Destroy()
+	if nextTokenID != e.eot {
+		e.logger.Warn("解码达到 MaxTokens 上限，结果可能被截断", "max_tokens", maxTokens)
 	}
 
-	return e.decode(generatedTokens), nil
+	rec.AddTokens(len(prompt), len(generatedTokens))
+
+	out.avgLogprob = sumLogprob / float64(len(generatedTokens))
+	out.tokens = generatedTokens
+	if n := len(out.tokens); n > 0 && out.tokens[n-1] == e.eot {
+		out.tokens = out.tokens[:n-1]
+	}
+	return out, nil
 }
 
 // createPastTensors 创建缓存张量
@@ -306,35 +539,40 @@ func (e *Engine) createPastTensors() (map[string]*ort.Value, error) {
 	return tensors, nil
 }
 
-// sampleTokenPrefill 获取词表中的最优 Token 索引（预解码）
-func (e *Engine) sampleTokenPrefill(logits *ort.Value) int {
+// sampleTokenPrefill 获取词表中的最优 Token 索引（预解码），同时返回其对数概率
+//
+// timestamps 为 true 时应用时间戳规则，否则屏蔽时间戳
+func (e *Engine) sampleTokenPrefill(logits *ort.Value, timestamps bool) (int, float64) {
 	data, _ := ort.GetTensorData[float32](logits)
 	shape, _ := logits.GetShape()
 
-	vocabSize := 51865
-
 	if len(shape) < 2 {
-		return e.eot
+		return e.eot, 0
 	}
 
 	promptLen := int(shape[1])
 	startIdx := (promptLen - 1) * vocabSize
 
 	if startIdx < 0 || startIdx+vocabSize > len(data) {
-		return e.eot
+		return e.eot, 0
+	}
+	raw := data[startIdx : startIdx+vocabSize]
+	scores := slices.Clone(raw)
+	if timestamps {
+		e.applyTimestampRules(scores, nil)
 	}
 
 	maxIdx := 0
 	maxVal := float32(-math.MaxFloat32)
 
 	for i := 0; i < vocabSize; i++ {
-		score := data[startIdx+i]
+		score := scores[i]
 
 		if i == e.noTime || i == e.sot || i == e.eot {
 			score = -float32(math.MaxFloat32)
 		}
 
-		if i >= 50364 {
+		if !timestamps && i >= e.timeBegin {
 			score = -float32(math.MaxFloat32)
 		}
 
@@ -344,17 +582,16 @@ func (e *Engine) sampleTokenPrefill(logits *ort.Value) int {
 		}
 	}
 
-	return maxIdx
+	return maxIdx, logProb(raw, maxIdx)
 }
 
-// sampleToken 获取词表中的最优 Token 索引（循环）
-func (e *Engine) sampleToken(logits *ort.Value, history []int) int {
+// sampleToken 获取词表中的最优 Token 索引（循环），同时返回其对数概率
+func (e *Engine) sampleToken(logits *ort.Value, history []int, timestamps bool) (int, float64) {
 	data, _ := ort.GetTensorData[float32](logits)
-	vocabSize := 51865
 	startIdx := len(data) - vocabSize
 
 	if startIdx < 0 || startIdx+vocabSize > len(data) {
-		return e.eot
+		return e.eot, 0
 	}
 
 	scores := make([]float32, vocabSize)
@@ -367,26 +604,30 @@ func (e *Engine) sampleToken(logits *ort.Value, history []int) int {
 	if e.sot >= 0 && e.sot < vocabSize {
 		scores[e.sot] = -float32(math.MaxFloat32)
 	}
-	if len(history) < 10 && e.eot >= 0 && e.eot < vocabSize {
+	// 生成时间戳时片段可能很短，由时间戳决定何时结束
+	if !timestamps && len(history) < 10 && e.eot >= 0 && e.eot < vocabSize {
 		scores[e.eot] = -float32(math.MaxFloat32)
 	}
 
-	// 重复惩罚
-	if len(history) > 0 {
-		lastToken := history[len(history)-1]
-		if lastToken >= 0 && lastToken < vocabSize && scores[lastToken] > -float32(math.MaxFloat32)/2 {
-			scores[lastToken] -= 1.0
+	// 重复惩罚，时间戳在相邻片段的边界处会合法地重复，不参与惩罚
+	penalize := func(id int, penalty float32) {
+		if id >= 0 && id < vocabSize && id < e.timeBegin && scores[id] > -float32(math.MaxFloat32)/2 {
+			scores[id] -= penalty
 		}
+	}
+	if len(history) > 0 {
+		penalize(history[len(history)-1], 1.0)
 		windowStart := 0
 		if len(history) > 5 {
 			windowStart = len(history) - 5
 		}
 		for _, histId := range history[windowStart:] {
-			if histId >= 0 && histId < vocabSize && scores[histId] > -float32(math.MaxFloat32)/2 {
-				scores[histId] -= 0.5
-			}
+			penalize(histId, 0.5)
 		}
 	}
+	if timestamps {
+		e.applyTimestampRules(scores, history)
+	}
 
 	maxIdx := 0
 	maxVal := float32(-math.MaxFloat32)
@@ -399,7 +640,7 @@ func (e *Engine) sampleToken(logits *ort.Value, history []int) int {
 		}
 	}
 
-	return maxIdx
+	return maxIdx, logProb(data[startIdx:startIdx+vocabSize], maxIdx)
 }
 
 // decode Token ids 转为文本
@@ -412,12 +653,12 @@ func (e *Engine) decode(ids []int) string {
 			break
 		}
 
-		// 跳过时长
-		if id >= 50364 {
+		// 跳过时间戳
+		if id >= e.timeBegin {
 			continue
 		}
 		// 跳过其他特殊 Token
-		if id >= 50257 && id < 50364 {
+		if id >= e.eot {
 			continue
 		}
 
@@ -453,5 +694,13 @@ func (e *Engine) Destroy() error {
 	if e.decSession != nil {
 		e.decSession.Destroy()
 	}
+	if e.onnx != nil {
+		e.onnx.Destroy()
+	}
 	return nil
 }
+
+// Close 释放相关资源，实现 asr.Recognizer 接口
+func (e *Engine) Close() error {
+	return e.Destroy()
+}
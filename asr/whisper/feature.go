@@ -1,6 +1,7 @@
 package whisper
 
 import (
+	"context"
 	"github.com/up-zero/gotool/mediautil"
 	"math"
 	"sync"
@@ -9,10 +10,6 @@ import (
 const (
 	// sampleRate 采样率
 	sampleRate = 16000
-	// channels 声道数
-	channels = 1
-	// bitsPerSample 采样位数
-	bitsPerSample = 16
 
 	nFFT    = 512
 	winLen  = 400
@@ -28,8 +25,8 @@ var (
 	specOnce   sync.Once
 )
 
-// extractFeatures 特征处理
-func (e *Engine) extractFeatures(samples []float32) ([]float32, error) {
+// extractFeatures 特征处理，ctx 结束时在帧之间中止
+func (e *Engine) extractFeatures(ctx context.Context, samples []float32) ([]float32, error) {
 	specOnce.Do(func() {
 		window = mediautil.HannWindow(winLen)
 		melFilters = mediautil.MelFilters(sampleRate, nFFT, nMel, 0, 0)
@@ -44,6 +41,9 @@ func (e *Engine) extractFeatures(samples []float32) ([]float32, error) {
 	fftBuffer := make([]complex128, nFFT)
 
 	for i := 0; i < nFr; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		start := i * hopLen
 		for j := 0; j < nFFT; j++ {
 			if j < winLen {
@@ -0,0 +1,125 @@
+package whisper
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"github.com/getcharzp/go-speech"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"math"
+	"slices"
+)
+
+// LanguageProb 语言及其概率
+type LanguageProb struct {
+	Language string  // 语言代码，例如 "zh"
+	Prob     float64 // 概率
+}
+
+// DetectLanguage 检测音频的语言
+//
+// 在 <|startoftranscript|> 之后运行一步 Decoder，对 added_tokens.json 中的语言 Token 计算概率，
+// 超过 30 秒的音频只使用前 30 秒
+//
+// # Params:
+//
+//	samples: 采样率 16KHz 的单声道音频数据，范围 [-1, 1]
+//
+// # Examples:
+//
+//	probs, err := asrEngine.DetectLanguage(samples)
+//	fmt.Println(probs[0].Language, probs[0].Prob) // zh 0.97
+func (e *Engine) DetectLanguage(samples []float32) ([]LanguageProb, error) {
+	return e.DetectLanguageContext(context.Background(), samples)
+}
+
+// DetectLanguageContext 与 DetectLanguage 相同，ctx 结束时在特征帧之间或推理阶段之间中止
+//
+// 返回的语言按概率从高到低排序，概率之和为 1
+func (e *Engine) DetectLanguageContext(ctx context.Context, samples []float32) (_ []LanguageProb, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	if len(samples) == 0 {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频数据为空", "")
+	}
+	hiddenState, err := e.encode(ctx, nil, samples)
+	if err != nil {
+		return nil, err
+	}
+	defer hiddenState.Destroy()
+	return e.detectLanguage(ctx, hiddenState)
+}
+
+// detectLanguage 根据 Encoder 输出计算语言 Token 的概率分布
+func (e *Engine) detectLanguage(ctx context.Context, encHiddenState *ort.Value) ([]LanguageProb, error) {
+	if len(e.languages) == 0 {
+		return nil, fmt.Errorf("added_tokens.json 中没有语言 Token，无法检测语言")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pastTensors, err := e.createPastTensors()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for _, t := range pastTensors {
+			t.Destroy()
+		}
+	}()
+
+	inputIdsTensor, err := ort.NewTensor([]int64{1, 1}, []int64{int64(e.sot)})
+	if err != nil {
+		return nil, fmt.Errorf("创建 input_ids tensor 失败: %w", err)
+	}
+	defer inputIdsTensor.Destroy()
+	useCacheTensor, err := ort.NewTensor([]int64{1}, []bool{false})
+	if err != nil {
+		return nil, fmt.Errorf("创建 use_cache_branch tensor 失败: %w", err)
+	}
+	defer useCacheTensor.Destroy()
+
+	inputs := map[string]*ort.Value{
+		"input_ids":             inputIdsTensor,
+		"encoder_hidden_states": encHiddenState,
+		"use_cache_branch":      useCacheTensor,
+	}
+	for name, value := range pastTensors {
+		inputs[name] = value
+	}
+	outputs, err := e.decSession.Run(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("语言检测推理失败: %w", err)
+	}
+	defer func() {
+		for _, v := range outputs {
+			v.Destroy()
+		}
+	}()
+
+	logits, err := ort.GetTensorData[float32](outputs["logits"])
+	if err != nil || len(logits) < vocabSize {
+		return nil, fmt.Errorf("获取 logits 失败: %v", err)
+	}
+	logits = logits[len(logits)-vocabSize:]
+
+	// 只在语言 Token 上做 softmax
+	maxV := -math.MaxFloat64
+	for id := range e.languages {
+		maxV = max(maxV, float64(logits[id]))
+	}
+	probs := make([]LanguageProb, 0, len(e.languages))
+	var sum float64
+	for id, lang := range e.languages {
+		p := math.Exp(float64(logits[id]) - maxV)
+		probs = append(probs, LanguageProb{Language: lang, Prob: p})
+		sum += p
+	}
+	for i := range probs {
+		probs[i].Prob /= sum
+	}
+	slices.SortFunc(probs, func(a, b LanguageProb) int {
+		return cmp.Or(cmp.Compare(b.Prob, a.Prob), cmp.Compare(a.Language, b.Language))
+	})
+	return probs, nil
+}
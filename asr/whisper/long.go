@@ -0,0 +1,68 @@
+package whisper
+
+import (
+	"context"
+	"errors"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+)
+
+// TranscribeLong 转录任意时长的音频，例如会议录音与播客
+//
+// 音频按 VAD 或静音切分为不超过 30 秒的语音段后逐段转录，时间戳偏移到整段音频，
+// Whisper 直接输出带标点的文本，不再额外预测标点
+//
+// # Params:
+//
+//	samples: 采样率 16KHz 的单声道音频数据，范围 [-1, 1]
+//	opt: 长音频转录可选参数，Splitter 可使用 *vad.Engine
+//
+// # Examples:
+//
+//	vadEngine, _ := vad.NewEngine(vad.DefaultConfig())
+//	result, err := asrEngine.TranscribeLong(samples, whisper.LongOption{
+//		TranscribeOption: whisper.TranscribeOption{Language: whisper.LangEn},
+//		Splitter:         vadEngine,
+//	})
+func (e *Engine) TranscribeLong(samples []float32, opt ...LongOption) (*asr.Result, error) {
+	return e.TranscribeLongContext(context.Background(), samples, opt...)
+}
+
+// TranscribeLongContext 与 TranscribeLong 相同，ctx 结束时在语音段之间或语音段内中止
+func (e *Engine) TranscribeLongContext(ctx context.Context, samples []float32, opt ...LongOption) (*asr.Result, error) {
+	return transcribeLong(ctx, samples, 1, opt, func(_ context.Context, fn func(e *Engine) error) error { return fn(e) })
+}
+
+// TranscribeLongWithPool 使用引擎池并行转录长音频中的语音段，并行度为池的实例数量
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	pool: 引擎池
+//	samples: 采样率 16KHz 的单声道音频数据，范围 [-1, 1]
+//	opt: 长音频转录可选参数
+func TranscribeLongWithPool(ctx context.Context, pool *speech.Pool[*Engine], samples []float32, opt ...LongOption) (*asr.Result, error) {
+	return transcribeLong(ctx, samples, pool.Stats().Size, opt, pool.Do)
+}
+
+// transcribeLong 长音频转录流程，do 获取一个引擎实例执行 fn，例如 Pool.Do
+func transcribeLong(ctx context.Context, samples []float32, workers int, opt []LongOption, do func(ctx context.Context, fn func(e *Engine) error) error) (_ *asr.Result, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	var o LongOption
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+
+	result, err := asr.TranscribeLong(ctx, samples, o.Splitter, chunkMaxDuration, workers,
+		func(ctx context.Context, samples []float32) (result *asr.Result, err error) {
+			err = do(ctx, func(e *Engine) error {
+				result, err = e.TranscribeContext(ctx, samples, o.TranscribeOption)
+				return err
+			})
+			return result, err
+		}, nil)
+	if errors.Is(err, asr.ErrEmptyStream) {
+		return nil, speech.NewError(speech.ErrEmptyAudio, engineName, "输入的音频数据为空", "")
+	}
+	return result, err
+}
@@ -0,0 +1,163 @@
+package whisper
+
+import (
+	"context"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"time"
+)
+
+const (
+	// timeStep 相邻时间戳 Token 之间的样本数 (20ms)
+	timeStep = sampleRate / 50
+	// maxPromptTokens 作为提示的前文 Token 数上限，与 OpenAI 一致取上下文长度的一半
+	maxPromptTokens = nTextCtx/2 - 1
+	// noSpeechThreshold, logprobThreshold <|nospeech|> 概率高于前者且平均对数概率低于后者时视为静音窗口
+	noSpeechThreshold = 0.6
+	logprobThreshold  = -1.0
+)
+
+// timedTokens 两个时间戳之间的 Token
+type timedTokens struct {
+	start, end int   // 在窗口中的样本位置
+	tokens     []int // 包含首尾的时间戳 Token
+}
+
+// transcribeSequential 按 OpenAI 的顺序长音频算法转录超过 30 秒的音频
+//
+// 每个窗口解码出带时间戳的片段后，窗口移动到最后一个完整片段的结束位置，
+// 已确认片段的 Token 作为 <|startofprev|> 之后的提示参与下一个窗口的解码
+//
+// first 为第一个窗口已有的 Encoder 输出 (例如检测语言时的编码结果)，为空时重新编码，由调用方释放
+func (e *Engine) transcribeSequential(ctx context.Context, rec *speech.Recorder, samples []float32, first *ort.Value, sot []int64, language string) (*asr.Result, error) {
+	result := &asr.Result{Language: language}
+	var history []int // 已确认的 Token
+	for seek := 0; seek < len(samples); {
+		window := samples[seek:min(seek+maxSmpl, len(samples))]
+		hiddenState := first
+		if seek > 0 || first == nil {
+			var err error
+			if hiddenState, err = e.encode(ctx, rec, window); err != nil {
+				return nil, err
+			}
+		}
+
+		prompt := make([]int64, 0, maxPromptTokens+len(sot)+1)
+		if len(history) > 0 {
+			prompt = append(prompt, int64(e.sop))
+			for _, id := range history[max(len(history)-maxPromptTokens, 0):] {
+				prompt = append(prompt, int64(id))
+			}
+		}
+		prompt = append(prompt, sot...)
+		out, err := e.runMergedDecoder(ctx, rec, hiddenState, prompt, true)
+		if hiddenState != first {
+			hiddenState.Destroy()
+		}
+		if err != nil {
+			return nil, err
+		}
+		rec.Stage("decoder")
+
+		if out.noSpeechProb > noSpeechThreshold && out.avgLogprob < logprobThreshold {
+			e.logger.Debug("跳过静音窗口", "offset", toDuration(seek), "no_speech_prob", out.noSpeechProb)
+			seek += len(window)
+			continue
+		}
+
+		parts, consumed := splitTimestamps(out.tokens, e.timeBegin, len(window))
+		for _, p := range parts {
+			history = append(history, p.tokens...)
+		}
+		result.Segments = append(result.Segments, e.segments(parts, seek)...)
+		seek += consumed
+	}
+	result.Text = segmentsText(result.Segments)
+	return result, nil
+}
+
+// segments 将窗口中的片段转换为结果，offset 为窗口在整段音频中的样本位置，没有文本的片段被丢弃
+func (e *Engine) segments(parts []timedTokens, offset int) []Segment {
+	var segments []Segment
+	for _, p := range parts {
+		text := e.decode(p.tokens)
+		if text == "" {
+			continue
+		}
+		segments = append(segments, Segment{
+			Start:  toDuration(offset + p.start),
+			End:    toDuration(offset + p.end),
+			Text:   text,
+			Tokens: p.tokens,
+		})
+	}
+	return segments
+}
+
+// segmentsText 拼接各片段的文本
+func segmentsText(segments []Segment) string {
+	texts := make([]string, len(segments))
+	for i, s := range segments {
+		texts[i] = s.Text
+	}
+	return asr.JoinText(texts)
+}
+
+// splitTimestamps 按连续的两个时间戳切分一个窗口的 Token，返回片段与窗口应前进的样本数
+//
+// 以单个时间戳结尾时整个窗口已转录完毕，否则最后一个片段可能不完整，窗口前进到最后一个完整片段的结束位置
+func splitTimestamps(tokens []int, timeBegin, windowLen int) ([]timedTokens, int) {
+	isTime := func(i int) bool { return tokens[i] >= timeBegin }
+	at := func(id int) int { return min((id-timeBegin)*timeStep, windowLen) }
+	n := len(tokens)
+	singleEnding := n >= 2 && !isTime(n-2) && isTime(n-1)
+
+	var cuts []int
+	for i := 1; i < n; i++ {
+		if isTime(i-1) && isTime(i) {
+			cuts = append(cuts, i)
+		}
+	}
+
+	if len(cuts) == 0 {
+		// 没有完整的片段，整个窗口作为一个片段
+		end := windowLen
+		for i := n - 1; i >= 0; i-- {
+			if isTime(i) {
+				if tokens[i] > timeBegin {
+					end = at(tokens[i])
+				}
+				break
+			}
+		}
+		return []timedTokens{{start: 0, end: end, tokens: tokens}}, windowLen
+	}
+
+	if singleEnding {
+		cuts = append(cuts, n)
+	}
+	var segments []timedTokens
+	last, prevEnd := 0, 0
+	for _, cut := range cuts {
+		s := timedTokens{start: prevEnd, end: prevEnd, tokens: tokens[last:cut]}
+		if isTime(last) {
+			s.start = at(tokens[last])
+		}
+		if isTime(cut - 1) {
+			s.end = max(at(tokens[cut-1]), s.start)
+		}
+		segments = append(segments, s)
+		last, prevEnd = cut, s.end
+	}
+	if singleEnding {
+		return segments, windowLen
+	}
+	// 窗口前进到最后一个完整片段的结束时间戳，至少前进一个时间戳的长度避免死循环
+	return segments, max(at(tokens[last-1]), timeStep)
+}
+
+// toDuration 样本数转换为时长
+func toDuration(samples int) time.Duration {
+	return time.Duration(samples) * time.Second / sampleRate
+}
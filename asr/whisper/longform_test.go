@@ -0,0 +1,66 @@
+package whisper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitTimestamps(t *testing.T) {
+	const tb, window = 100, 30 * sampleRate
+	cases := []struct {
+		name     string
+		tokens   []int
+		want     []timedTokens
+		consumed int
+	}{
+		{
+			name:   "最后一个片段不完整时前进到最后一个完整片段",
+			tokens: []int{tb, 1, 2, tb + 100, tb + 100, 3, tb + 250, tb + 250, 4},
+			want: []timedTokens{
+				{start: 0, end: 32000, tokens: []int{tb, 1, 2, tb + 100}},
+				{start: 32000, end: 80000, tokens: []int{tb + 100, 3, tb + 250}},
+			},
+			consumed: 80000,
+		},
+		{
+			name:   "以单个时间戳结尾时整个窗口转录完毕",
+			tokens: []int{tb, 1, tb + 50, tb + 50, 2, tb + 80},
+			want: []timedTokens{
+				{start: 0, end: 16000, tokens: []int{tb, 1, tb + 50}},
+				{start: 16000, end: 25600, tokens: []int{tb + 50, 2, tb + 80}},
+			},
+			consumed: window,
+		},
+		{
+			name:     "没有成对时间戳时以最后一个时间戳结束",
+			tokens:   []int{tb, 1, 2, tb + 40},
+			want:     []timedTokens{{start: 0, end: 12800, tokens: []int{tb, 1, 2, tb + 40}}},
+			consumed: window,
+		},
+		{
+			name:     "没有时间戳",
+			tokens:   []int{1, 2, 3},
+			want:     []timedTokens{{start: 0, end: window, tokens: []int{1, 2, 3}}},
+			consumed: window,
+		},
+		{
+			name:     "空窗口",
+			want:     []timedTokens{{start: 0, end: window}},
+			consumed: window,
+		},
+		{
+			name:     "至少前进一个时间戳",
+			tokens:   []int{tb, tb},
+			want:     []timedTokens{{start: 0, end: 0, tokens: []int{tb}}},
+			consumed: timeStep,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, consumed := splitTimestamps(c.tokens, tb, window)
+			if !reflect.DeepEqual(got, c.want) || consumed != c.consumed {
+				t.Fatalf("got %v, %d, want %v, %d", got, consumed, c.want, c.consumed)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package whisper
+
+import "math"
+
+// maxInitialTimestamp 第一个时间戳的最大值 (1 秒，即 50 个时间戳 Token)
+const maxInitialTimestamp = 50
+
+// applyTimestampRules 按 Whisper 的时间戳规则屏蔽 scores 中不合法的 Token
+//
+//   - 第一个 Token 必须是不晚于 1 秒的时间戳
+//   - 时间戳成对出现：两个连续的时间戳之后必须是文本，单个时间戳之后只能是时间戳或 <|endoftext|>
+//   - 时间戳单调不减，成对的结束时间戳与下一个开始时间戳可以相同
+//   - 时间戳的总概率高于任一文本 Token 时必须生成时间戳
+//
+// # Params:
+//
+//	scores: 当前步的 logits，被原地修改
+//	history: 本窗口已生成的 Token，不含提示
+func (e *Engine) applyTimestampRules(scores []float32, history []int) {
+	suppress := func(from, to int) {
+		for i := max(from, 0); i < min(to, len(scores)); i++ {
+			scores[i] = -math.MaxFloat32
+		}
+	}
+	isTime := func(id int) bool { return id >= e.timeBegin }
+	suppress(e.noTime, e.noTime+1)
+
+	if len(history) == 0 {
+		suppress(0, e.timeBegin)
+		suppress(e.timeBegin+maxInitialTimestamp+1, len(scores))
+		return
+	}
+
+	n := len(history)
+	lastWasTime := isTime(history[n-1])
+	penultimateWasTime := n < 2 || isTime(history[n-2])
+	if lastWasTime {
+		if penultimateWasTime {
+			suppress(e.timeBegin, len(scores))
+		} else {
+			suppress(0, e.eot)
+		}
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		if !isTime(history[i]) {
+			continue
+		}
+		last := history[i] + 1
+		if lastWasTime && !penultimateWasTime {
+			last = history[i]
+		}
+		suppress(e.timeBegin, last)
+		break
+	}
+
+	// 比较时间戳的总概率与概率最大的文本 Token
+	maxV := float32(-math.MaxFloat32)
+	for _, v := range scores {
+		maxV = max(maxV, v)
+	}
+	var timeSum float64
+	maxText := -math.MaxFloat64
+	for i, v := range scores {
+		if i >= e.timeBegin {
+			timeSum += math.Exp(float64(v) - float64(maxV))
+		} else {
+			maxText = max(maxText, float64(v))
+		}
+	}
+	if timeSum > 0 && math.Log(timeSum) > maxText-float64(maxV) {
+		suppress(0, e.timeBegin)
+	}
+}
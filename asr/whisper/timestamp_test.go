@@ -0,0 +1,71 @@
+package whisper
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyTimestampRules(t *testing.T) {
+	e := &Engine{eot: 90, noTime: 99, timeBegin: 100}
+	tb := e.timeBegin
+	cases := []struct {
+		name       string
+		history    []int
+		tsScore    float32 // 时间戳 Token 的分数，文本 Token 为 10
+		suppressed []int
+		allowed    []int
+	}{
+		{
+			name:       "第一个 Token 是不晚于 1 秒的时间戳",
+			suppressed: []int{0, 5, e.noTime, tb + maxInitialTimestamp + 1, tb + 300},
+			allowed:    []int{tb, tb + maxInitialTimestamp},
+		},
+		{
+			name:       "文本之后时间戳单调递增",
+			history:    []int{tb + 10, 5},
+			suppressed: []int{tb, tb + 10},
+			allowed:    []int{5, e.eot, tb + 11, tb + 300},
+		},
+		{
+			name:       "单个时间戳之后只能是时间戳或结束",
+			history:    []int{tb + 10, 5, tb + 20},
+			suppressed: []int{0, 5, e.eot - 1, tb + 19},
+			allowed:    []int{e.eot, tb + 20, tb + 21},
+		},
+		{
+			name:       "成对的时间戳之后是文本",
+			history:    []int{tb, 5, tb + 20, tb + 20},
+			suppressed: []int{tb, tb + 20, tb + 21, tb + 300},
+			allowed:    []int{0, 5, e.eot},
+		},
+		{
+			name:       "时间戳总概率高于文本时必须生成时间戳",
+			history:    []int{tb, 5},
+			tsScore:    9,
+			suppressed: []int{0, 5, tb},
+			allowed:    []int{tb + 1, tb + 300},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			scores := make([]float32, tb+301)
+			for i := range scores {
+				scores[i] = 10
+				if i >= tb {
+					scores[i] = c.tsScore
+				}
+			}
+			e.applyTimestampRules(scores, c.history)
+			for _, id := range c.suppressed {
+				if scores[id] != -math.MaxFloat32 {
+					t.Fatalf("token %d not suppressed", id)
+				}
+			}
+			for _, id := range c.allowed {
+				if scores[id] == -math.MaxFloat32 {
+					t.Fatalf("token %d suppressed", id)
+				}
+			}
+		})
+	}
+}
@@ -2,9 +2,9 @@ package whisper
 
 import (
 	"encoding/json"
-	"fmt"
-	"github.com/up-zero/gotool/mediautil"
+	"math"
 	"os"
+	"slices"
 	"sync"
 )
 
@@ -87,11 +87,12 @@ func initByteDecoder() {
 	})
 }
 
-// parseWavBytes 转换 WAV 字节流
-func parseWavBytes(wavBytes []byte) ([]float32, error) {
-	targetBytes, err := mediautil.ReformatWavBytes(wavBytes, sampleRate, channels, bitsPerSample)
-	if err != nil {
-		return nil, fmt.Errorf("无法格式化 WAV 文件: %v", err)
+// logProb 返回 logits 经过 softmax 后第 id 个元素的对数概率
+func logProb(logits []float32, id int) float64 {
+	maxV := slices.Max(logits)
+	var sum float64
+	for _, v := range logits {
+		sum += math.Exp(float64(v - maxV))
 	}
-	return mediautil.PcmBytesToFloat32(targetBytes[44:], 16)
+	return float64(logits[id]-maxV) - math.Log(sum)
 }
@@ -0,0 +1,77 @@
+// Package audio 提供纯 Go 实现的音频解码与处理，供 ASR 与 TTS 引擎共享
+package audio
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTruncated 音频数据不完整
+	ErrTruncated = errors.New("audio: truncated data")
+	// ErrUnsupportedFormat 不支持的音频格式
+	ErrUnsupportedFormat = errors.New("audio: unsupported format")
+	// ErrInvalidFormat 音频头部或数据格式错误
+	ErrInvalidFormat = errors.New("audio: invalid format")
+)
+
+// Buffer 解码后的 PCM 音频
+type Buffer struct {
+	SampleRate int       // 采样率
+	Channels   int       // 声道数
+	Data       []float32 // 按帧交错存储的样本，范围 [-1, 1]
+}
+
+// Frames 返回帧数 (每帧包含所有声道的一个样本)
+func (b *Buffer) Frames() int {
+	if b.Channels <= 0 {
+		return 0
+	}
+	return len(b.Data) / b.Channels
+}
+
+// Duration 返回音频时长
+func (b *Buffer) Duration() time.Duration {
+	if b.SampleRate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(b.Frames()) / float64(b.SampleRate) * float64(time.Second))
+}
+
+// Channel 返回第 ch 个声道的样本
+func (b *Buffer) Channel(ch int) []float32 {
+	if ch < 0 || ch >= b.Channels {
+		return nil
+	}
+	if b.Channels == 1 {
+		return b.Data
+	}
+	out := make([]float32, b.Frames())
+	for i := range out {
+		out[i] = b.Data[i*b.Channels+ch]
+	}
+	return out
+}
+
+// Mono 返回所有声道取平均后的单声道样本
+func (b *Buffer) Mono() []float32 {
+	return Downmix(b.Data, b.Channels)
+}
+
+// Downmix 将交错存储的多声道样本取平均转换为单声道
+func Downmix(data []float32, channels int) []float32 {
+	if channels <= 1 {
+		return data
+	}
+	frames := len(data) / channels
+	out := make([]float32, frames)
+	scale := 1 / float32(channels)
+	for i := range out {
+		var sum float32
+		for _, v := range data[i*channels : (i+1)*channels] {
+			sum += v
+		}
+		out[i] = sum * scale
+	}
+	return out
+}
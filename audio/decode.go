@@ -0,0 +1,114 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/hajimehoshi/go-mp3"
+	"io"
+)
+
+// Format 音频文件格式
+type Format string
+
+const (
+	FormatUnknown Format = ""     // 无法识别
+	FormatWAV     Format = "wav"  // RIFF / RF64 / BW64 WAV
+	FormatFLAC    Format = "flac" // FLAC
+	FormatMP3     Format = "mp3"  // MPEG Layer III
+)
+
+// Sniff 根据文件头部的魔数识别音频格式
+func Sniff(data []byte) Format {
+	if len(data) >= 12 && string(data[8:12]) == "WAVE" {
+		switch string(data[:4]) {
+		case "RIFF", "RF64", "BW64":
+			return FormatWAV
+		}
+	}
+	// FLAC 与 MP3 文件开头都可能带有 ID3v2 标签
+	data = skipID3v2(data)
+	switch {
+	case len(data) >= 4 && string(data[:4]) == "fLaC":
+		return FormatFLAC
+	case len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 && data[1]>>1&0x3 == 0x1:
+		// MPEG 帧同步码 11 位，Layer III
+		return FormatMP3
+	}
+	return FormatUnknown
+}
+
+// Decode 识别格式并解码 WAV、FLAC 或 MP3 字节流
+//
+// # Examples:
+//
+//	buf, err := audio.Decode(data)
+//	samples := audio.Resample(buf.Mono(), buf.SampleRate, 16000)
+func Decode(data []byte) (*Buffer, error) {
+	switch Sniff(data) {
+	case FormatWAV:
+		return DecodeWAV(data)
+	case FormatFLAC:
+		return DecodeFLAC(data)
+	case FormatMP3:
+		return DecodeMP3(data)
+	}
+	return nil, fmt.Errorf("%w: 无法识别的音频格式，支持 WAV、FLAC、MP3", ErrUnsupportedFormat)
+}
+
+// DecodeMono 解码 WAV、FLAC 或 MP3 字节流，混合为单声道并重采样到 sampleRate
+//
+// # Examples:
+//
+//	samples, err := audio.DecodeMono(data, 16000)
+func DecodeMono(data []byte, sampleRate int) ([]float32, error) {
+	buf, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	return Resample(buf.Mono(), buf.SampleRate, sampleRate), nil
+}
+
+// DecodeMP3 解码 MPEG-1/2/2.5 Layer III 字节流，输出为双声道
+func DecodeMP3(data []byte) (*Buffer, error) {
+	d, err := mp3.NewDecoder(bytes.NewReader(data))
+	if err != nil {
+		return nil, mp3Error(err)
+	}
+	pcm, err := io.ReadAll(d)
+	if err != nil {
+		return nil, mp3Error(err)
+	}
+	if len(pcm) == 0 {
+		return nil, fmt.Errorf("%w: 未找到 MP3 帧", ErrInvalidFormat)
+	}
+	// go-mp3 固定输出 16 位小端双声道 PCM
+	samples := make([]float32, len(pcm)/4*2)
+	decodeS16(pcm[:len(samples)*2], samples)
+	return &Buffer{SampleRate: d.SampleRate(), Channels: 2, Data: samples}, nil
+}
+
+// mp3Error 将 MP3 解码错误转换为 audio 包的错误类型
+func mp3Error(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: MP3 数据不完整", ErrTruncated)
+	}
+	return fmt.Errorf("%w: MP3 解码失败: %v", ErrInvalidFormat, err)
+}
+
+// skipID3v2 跳过开头的 ID3v2 标签
+func skipID3v2(data []byte) []byte {
+	for len(data) >= 10 && string(data[:3]) == "ID3" {
+		// 标签大小为 4 字节 synchsafe 整数，不含 10 字节头部，footer 标志位表示额外 10 字节
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		size += 10
+		if data[5]&0x10 != 0 {
+			size += 10
+		}
+		if size > len(data) {
+			return nil
+		}
+		data = data[size:]
+	}
+	return data
+}
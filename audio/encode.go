@@ -0,0 +1,210 @@
+package audio
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Encoding 样本编码
+type Encoding string
+
+const (
+	EncodingS16LE Encoding = "s16le" // 16 位有符号整数，小端
+	EncodingF32LE Encoding = "f32le" // 32 位 IEEE 浮点，小端
+	EncodingMuLaw Encoding = "mulaw" // G.711 μ-law，8 位
+	EncodingALaw  Encoding = "alaw"  // G.711 A-law，8 位
+)
+
+// Container 封装格式
+type Container string
+
+const (
+	ContainerRaw Container = "raw" // 无头部的裸数据
+	ContainerWAV Container = "wav" // WAV，带有与编码对应的格式标签
+)
+
+// OutputFormat 输出音频格式描述
+type OutputFormat struct {
+	Encoding   Encoding  // 样本编码，默认 s16le
+	SampleRate int       // 采样率，0 表示保持源采样率，不同时进行带限重采样
+	Channels   int       // 声道数，0 表示保持源声道数；单声道可复制为多声道，多声道可混合为单声道
+	Container  Container // 封装格式，默认 raw
+	Quality    Quality   // 重采样质量，默认 QualityMedium
+}
+
+// 常用输出格式
+var (
+	// OutputWAV 16 位 PCM WAV，保持源采样率
+	OutputWAV = OutputFormat{Encoding: EncodingS16LE, Container: ContainerWAV}
+	// OutputMuLaw8k 8KHz 单声道 μ-law 裸数据，适用于北美与日本电话网络
+	OutputMuLaw8k = OutputFormat{Encoding: EncodingMuLaw, SampleRate: 8000, Channels: 1}
+	// OutputALaw8k 8KHz 单声道 A-law 裸数据，适用于欧洲与中国电话网络
+	OutputALaw8k = OutputFormat{Encoding: EncodingALaw, SampleRate: 8000, Channels: 1}
+)
+
+// Validate 检查格式参数
+func (f OutputFormat) Validate() error {
+	switch f.Encoding {
+	case "", EncodingS16LE, EncodingF32LE, EncodingMuLaw, EncodingALaw:
+	default:
+		return fmt.Errorf("%w: 样本编码 %q", ErrUnsupportedFormat, f.Encoding)
+	}
+	switch f.Container {
+	case "", ContainerRaw, ContainerWAV:
+	default:
+		return fmt.Errorf("%w: 封装格式 %q", ErrUnsupportedFormat, f.Container)
+	}
+	if f.SampleRate < 0 {
+		return fmt.Errorf("%w: 采样率 %d", ErrInvalidFormat, f.SampleRate)
+	}
+	if f.Channels < 0 || f.Channels > maxChannels {
+		return fmt.Errorf("%w: 声道数 %d", ErrInvalidFormat, f.Channels)
+	}
+	if _, ok := qualityParams[f.Quality]; f.Quality != 0 && !ok {
+		return fmt.Errorf("%w: 重采样质量 %d", ErrInvalidFormat, f.Quality)
+	}
+	return nil
+}
+
+// BytesPerSample 返回每个样本占用的字节数
+func (e Encoding) BytesPerSample() int {
+	switch e {
+	case EncodingF32LE:
+		return 4
+	case EncodingMuLaw, EncodingALaw:
+		return 1
+	}
+	return 2
+}
+
+// Encode 按照输出格式转换采样率与声道数，并编码为字节流
+//
+// # Params:
+//
+//	buf: 源音频
+//	f: 输出格式
+//
+// # Examples:
+//
+//	data, err := audio.Encode(&audio.Buffer{SampleRate: 22050, Channels: 1, Data: pcm}, audio.OutputMuLaw8k)
+func Encode(buf *Buffer, f OutputFormat) ([]byte, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	if buf.SampleRate <= 0 || buf.Channels <= 0 {
+		return nil, fmt.Errorf("%w: 源采样率 %d, 声道数 %d", ErrInvalidFormat, buf.SampleRate, buf.Channels)
+	}
+	rate := cmp.Or(f.SampleRate, buf.SampleRate)
+	channels := cmp.Or(f.Channels, buf.Channels)
+
+	// 声道转换，单声道与多声道互转时先得到单声道，写出时再复制到各声道
+	var planes [][]float32
+	switch {
+	case channels == buf.Channels:
+		for ch := 0; ch < channels; ch++ {
+			planes = append(planes, buf.Channel(ch))
+		}
+	case channels == 1 || buf.Channels == 1:
+		planes = [][]float32{buf.Mono()}
+	default:
+		return nil, fmt.Errorf("%w: 无法将 %d 声道转换为 %d 声道", ErrUnsupportedFormat, buf.Channels, channels)
+	}
+
+	// 采样率转换
+	if rate != buf.SampleRate {
+		for i, p := range planes {
+			planes[i] = ResampleQuality(p, buf.SampleRate, rate, f.Quality)
+		}
+	}
+
+	enc := f.Encoding
+	if enc == "" {
+		enc = EncodingS16LE
+	}
+	frames := len(planes[0])
+	dataSize := frames * channels * enc.BytesPerSample()
+
+	var out []byte
+	if f.Container == ContainerWAV {
+		if int64(dataSize) > math.MaxUint32-64 {
+			return nil, fmt.Errorf("%w: WAV 数据长度 %d 超出 4GB", ErrUnsupportedFormat, dataSize)
+		}
+		out = appendWavHeader(make([]byte, 0, dataSize+58), enc, rate, channels, frames)
+	} else {
+		out = make([]byte, 0, dataSize)
+	}
+
+	frame := make([]float32, channels)
+	for i := 0; i < frames; i++ {
+		for ch := range frame {
+			frame[ch] = planes[min(ch, len(planes)-1)][i]
+		}
+		out = AppendSamples(out, frame, enc)
+	}
+	return out, nil
+}
+
+// AppendSamples 将样本按指定编码追加到 dst，超出 [-1, 1] 的样本会被截断
+func AppendSamples(dst []byte, samples []float32, enc Encoding) []byte {
+	for _, v := range samples {
+		v = clampSample(v)
+		switch enc {
+		case EncodingF32LE:
+			dst = binary.LittleEndian.AppendUint32(dst, math.Float32bits(v))
+		case EncodingMuLaw:
+			dst = append(dst, LinearToMuLaw(toInt16(v)))
+		case EncodingALaw:
+			dst = append(dst, LinearToALaw(toInt16(v)))
+		default:
+			dst = binary.LittleEndian.AppendUint16(dst, uint16(toInt16(v)))
+		}
+	}
+	return dst
+}
+
+// appendWavHeader 写入 WAV 头部，非 PCM 编码按规范写入 cbSize 与 fact 块
+func appendWavHeader(dst []byte, enc Encoding, rate, channels, frames int) []byte {
+	bytesPerSample := enc.BytesPerSample()
+	dataSize := frames * channels * bytesPerSample
+
+	tag := uint16(WavFormatPCM)
+	switch enc {
+	case EncodingF32LE:
+		tag = WavFormatIEEEFloat
+	case EncodingMuLaw:
+		tag = WavFormatMuLaw
+	case EncodingALaw:
+		tag = WavFormatALaw
+	}
+	fmtSize, factSize := 16, 0
+	if tag != WavFormatPCM {
+		fmtSize, factSize = 18, 12
+	}
+
+	le := binary.LittleEndian
+	dst = append(dst, "RIFF"...)
+	dst = le.AppendUint32(dst, uint32(4+8+fmtSize+factSize+8+dataSize))
+	dst = append(dst, "WAVEfmt "...)
+	dst = le.AppendUint32(dst, uint32(fmtSize))
+	dst = le.AppendUint16(dst, tag)
+	dst = le.AppendUint16(dst, uint16(channels))
+	dst = le.AppendUint32(dst, uint32(rate))
+	dst = le.AppendUint32(dst, uint32(rate*channels*bytesPerSample))
+	dst = le.AppendUint16(dst, uint16(channels*bytesPerSample))
+	dst = le.AppendUint16(dst, uint16(bytesPerSample*8))
+	if tag != WavFormatPCM {
+		dst = le.AppendUint16(dst, 0) // cbSize
+		dst = append(dst, "fact"...)
+		dst = le.AppendUint32(dst, 4)
+		dst = le.AppendUint32(dst, uint32(frames))
+	}
+	dst = append(dst, "data"...)
+	return le.AppendUint32(dst, uint32(dataSize))
+}
+
+// toInt16 将 [-1, 1] 的样本量化为 16 位整数
+func toInt16(v float32) int16 {
+	return int16(math.Round(float64(v) * 32767))
+}
@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestG711(t *testing.T) {
+	// ITU-T G.711 静音码字
+	if b := LinearToMuLaw(0); b != 0xFF {
+		t.Fatalf("μ-law silence: got 0x%02X", b)
+	}
+	if b := LinearToALaw(0); b != 0xD5 {
+		t.Fatalf("A-law silence: got 0x%02X", b)
+	}
+
+	// 编码后再解码，误差不超过所在段的量化步长
+	for s := math.MinInt16; s <= math.MaxInt16; s += 7 {
+		v := int16(s)
+		mu := int(MuLawToLinear(LinearToMuLaw(v)))
+		al := int(ALawToLinear(LinearToALaw(v)))
+		tolerance := max(abs(s)/16, 16)
+		if abs(mu-s) > tolerance || abs(al-s) > tolerance {
+			t.Fatalf("%d: μ-law %d, A-law %d", s, mu, al)
+		}
+	}
+
+	// 所有码字解码后再编码保持不变 (μ-law 的 0x7F 与 0xFF 均表示 0)
+	for b := 0; b < 256; b++ {
+		if b != 0x7F && LinearToMuLaw(MuLawToLinear(byte(b))) != byte(b) {
+			t.Fatalf("μ-law codeword 0x%02X not stable", b)
+		}
+		if LinearToALaw(ALawToLinear(byte(b))) != byte(b) {
+			t.Fatalf("A-law codeword 0x%02X not stable", b)
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	src := &Buffer{SampleRate: 16000, Channels: 1, Data: []float32{0, 0.5, -0.5, 1, -1, 0.25}}
+
+	for _, enc := range []Encoding{EncodingS16LE, EncodingF32LE, EncodingMuLaw, EncodingALaw} {
+		data, err := Encode(src, OutputFormat{Encoding: enc, Channels: 2, Container: ContainerWAV})
+		if err != nil {
+			t.Fatalf("%s: %v", enc, err)
+		}
+		buf, err := DecodeWAV(data)
+		if err != nil {
+			t.Fatalf("%s: %v", enc, err)
+		}
+		if buf.SampleRate != 16000 || buf.Channels != 2 || buf.Frames() != len(src.Data) {
+			t.Fatalf("%s: rate %d, channels %d, frames %d", enc, buf.SampleRate, buf.Channels, buf.Frames())
+		}
+		for i, want := range src.Data {
+			l, r := buf.Data[2*i], buf.Data[2*i+1]
+			if l != r || math.Abs(float64(l-want)) > 0.02 {
+				t.Fatalf("%s: frame %d: got (%v, %v), want %v", enc, i, l, r, want)
+			}
+		}
+	}
+
+	raw, err := Encode(src, OutputFormat{})
+	if err != nil || len(raw) != len(src.Data)*2 || int16(binary.LittleEndian.Uint16(raw[2:])) != 16384 {
+		t.Fatalf("raw s16le: %v %v", raw, err)
+	}
+
+	stereo := &Buffer{SampleRate: 16000, Channels: 2, Data: make([]float32, 3200)}
+	ulaw, err := Encode(stereo, OutputMuLaw8k)
+	if err != nil || len(ulaw) != 800 {
+		t.Fatalf("μ-law 8k: %d bytes, %v", len(ulaw), err)
+	}
+
+	if _, err := Encode(stereo, OutputFormat{Channels: 3}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("channel layout: %v", err)
+	}
+	if _, err := Encode(src, OutputFormat{Encoding: "opus"}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("encoding: %v", err)
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,527 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// flacMaxBlockSize FLAC 帧的最大块大小
+const flacMaxBlockSize = 65535
+
+// errFlacEOF 位读取越界，转换为 ErrTruncated
+var errFlacEOF = errors.New("flac: unexpected end of data")
+
+// FlacInfo FLAC 的 STREAMINFO 信息
+type FlacInfo struct {
+	SampleRate    int   // 采样率
+	Channels      int   // 声道数
+	BitsPerSample int   // 每个样本的位数
+	TotalSamples  int64 // 每个声道的总样本数，0 表示未知
+}
+
+// DecodeFLAC 解码 FLAC 字节流
+//
+// 支持 CONSTANT、VERBATIM、FIXED、LPC 子帧与全部立体声去相关方式，文件开头的 ID3v2 标签会被跳过。
+// 不校验帧 CRC 与 MD5
+func DecodeFLAC(data []byte) (*Buffer, error) {
+	data = skipID3v2(data)
+	if len(data) < 4 || string(data[:4]) != "fLaC" {
+		return nil, fmt.Errorf("%w: 不是 FLAC 文件", ErrInvalidFormat)
+	}
+	info, offset, err := parseFlacMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &flacDecoder{info: info, br: bitReader{data: data, pos: offset * 8}}
+	// 预分配时按数据长度限制容量，避免异常的 STREAMINFO 导致过大的内存分配
+	out := make([]float32, 0, min(info.TotalSamples*int64(info.Channels), int64(len(data))*4))
+	for frames := 0; ; frames++ {
+		if d.br.pos/8+2 > len(data) {
+			break
+		}
+		// 帧同步码 0b11111111111110，不匹配时视为文件末尾的附加数据 (例如 ID3v1 标签)
+		if data[d.br.pos/8] != 0xFF || data[d.br.pos/8+1]&0xFE != 0xF8 {
+			if frames == 0 {
+				return nil, fmt.Errorf("%w: 未找到 FLAC 帧", ErrInvalidFormat)
+			}
+			break
+		}
+		if out, err = d.decodeFrame(out); err != nil {
+			if errors.Is(err, errFlacEOF) {
+				return nil, fmt.Errorf("%w: FLAC 第 %d 帧数据不完整", ErrTruncated, frames)
+			}
+			return nil, fmt.Errorf("FLAC 第 %d 帧: %w", frames, err)
+		}
+	}
+	return &Buffer{SampleRate: info.SampleRate, Channels: info.Channels, Data: out}, nil
+}
+
+// parseFlacMetadata 解析元数据块，返回 STREAMINFO 与第一个音频帧的字节偏移
+func parseFlacMetadata(data []byte) (FlacInfo, int, error) {
+	var info FlacInfo
+	var haveInfo bool
+	pos := 4
+	for {
+		if pos+4 > len(data) {
+			return info, 0, fmt.Errorf("%w: 读取 FLAC 元数据时文件提前结束", ErrTruncated)
+		}
+		last := data[pos]&0x80 != 0
+		typ := data[pos] & 0x7F
+		size := int(data[pos+1])<<16 | int(data[pos+2])<<8 | int(data[pos+3])
+		pos += 4
+		if pos+size > len(data) {
+			return info, 0, fmt.Errorf("%w: 读取 FLAC 元数据时文件提前结束", ErrTruncated)
+		}
+		if typ == 0 {
+			if size < 34 {
+				return info, 0, fmt.Errorf("%w: STREAMINFO 长度 %d 错误", ErrInvalidFormat, size)
+			}
+			// 跳过块大小与帧大小 (10 字节)，随后为 20 位采样率、3 位声道数、5 位位深、36 位总样本数
+			v := binary.BigEndian.Uint64(data[pos+10 : pos+18])
+			info.SampleRate = int(v >> 44)
+			info.Channels = int(v>>41&0x7) + 1
+			info.BitsPerSample = int(v>>36&0x1F) + 1
+			info.TotalSamples = int64(v & (1<<36 - 1))
+			haveInfo = true
+		}
+		pos += size
+		if last {
+			break
+		}
+	}
+	if !haveInfo {
+		return info, 0, fmt.Errorf("%w: 缺少 STREAMINFO", ErrInvalidFormat)
+	}
+	if info.SampleRate == 0 || info.BitsPerSample < 4 {
+		return info, 0, fmt.Errorf("%w: 采样率 %d, 位深 %d", ErrUnsupportedFormat, info.SampleRate, info.BitsPerSample)
+	}
+	return info, pos, nil
+}
+
+// flacDecoder 帧解码状态
+type flacDecoder struct {
+	info     FlacInfo
+	br       bitReader
+	channels [8][]int64 // 各声道的解码缓冲
+}
+
+// flacBlockSizes 块大小编码 (0、6、7 需特殊处理)
+var flacBlockSizes = [16]int{0, 192, 576, 1152, 2304, 4608, 0, 0, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
+
+// flacSampleSizes 位深编码，0 表示使用 STREAMINFO，-1 为保留值
+var flacSampleSizes = [8]int{0, 8, 12, -1, 16, 20, 24, 32}
+
+// decodeFrame 解码一个音频帧，将交错后的样本追加到 out
+func (d *flacDecoder) decodeFrame(out []float32) ([]float32, error) {
+	br := &d.br
+	// 同步码 14 位 + 保留位 + 分块策略
+	if _, err := br.read(16); err != nil {
+		return out, err
+	}
+	v, err := br.read(16)
+	if err != nil {
+		return out, err
+	}
+	bsCode, srCode := v>>12, v>>8&0xF
+	assignment, ssCode := int(v>>4&0xF), v>>1&0x7
+
+	// UTF-8 编码的帧号或样本号
+	if err := br.skipUTF8(); err != nil {
+		return out, err
+	}
+
+	blockSize := flacBlockSizes[bsCode]
+	switch bsCode {
+	case 0:
+		return out, fmt.Errorf("%w: 保留的块大小编码", ErrInvalidFormat)
+	case 6:
+		n, err := br.read(8)
+		if err != nil {
+			return out, err
+		}
+		blockSize = int(n) + 1
+	case 7:
+		n, err := br.read(16)
+		if err != nil {
+			return out, err
+		}
+		blockSize = int(n) + 1
+	}
+	if blockSize > flacMaxBlockSize {
+		return out, fmt.Errorf("%w: 块大小 %d 过大", ErrInvalidFormat, blockSize)
+	}
+
+	// 采样率以 STREAMINFO 为准，仅跳过帧头中显式编码的部分
+	switch srCode {
+	case 12:
+		_, err = br.read(8)
+	case 13, 14:
+		_, err = br.read(16)
+	case 15:
+		return out, fmt.Errorf("%w: 无效的采样率编码", ErrInvalidFormat)
+	}
+	if err != nil {
+		return out, err
+	}
+
+	// CRC-8
+	if _, err := br.read(8); err != nil {
+		return out, err
+	}
+
+	bps := flacSampleSizes[ssCode]
+	switch {
+	case bps == 0:
+		bps = d.info.BitsPerSample
+	case bps < 0:
+		return out, fmt.Errorf("%w: 保留的位深编码", ErrInvalidFormat)
+	}
+
+	channels := assignment + 1
+	if assignment >= 8 {
+		if assignment > 10 {
+			return out, fmt.Errorf("%w: 保留的声道编码 %d", ErrInvalidFormat, assignment)
+		}
+		channels = 2
+	}
+	if channels != d.info.Channels {
+		return out, fmt.Errorf("%w: 帧声道数 %d 与 STREAMINFO 不一致", ErrInvalidFormat, channels)
+	}
+
+	for ch := 0; ch < channels; ch++ {
+		// 侧声道多 1 位
+		chBps := bps
+		if (assignment == 8 || assignment == 10) && ch == 1 || assignment == 9 && ch == 0 {
+			chBps++
+		}
+		if cap(d.channels[ch]) < blockSize {
+			d.channels[ch] = make([]int64, blockSize)
+		}
+		d.channels[ch] = d.channels[ch][:blockSize]
+		if err := d.decodeSubframe(d.channels[ch], chBps); err != nil {
+			return out, err
+		}
+	}
+
+	// 对齐到字节后读取 CRC-16
+	br.align()
+	if _, err := br.read(16); err != nil {
+		return out, err
+	}
+
+	a, b := d.channels[0], d.channels[1]
+	switch assignment {
+	case 8: // left/side
+		for i := range b {
+			b[i] = a[i] - b[i]
+		}
+	case 9: // side/right
+		for i := range a {
+			a[i] += b[i]
+		}
+	case 10: // mid/side
+		for i := range a {
+			mid, side := a[i]<<1|b[i]&1, b[i]
+			a[i], b[i] = (mid+side)>>1, (mid-side)>>1
+		}
+	}
+
+	scale := 1 / float32(int64(1)<<(bps-1))
+	for i := 0; i < blockSize; i++ {
+		for ch := 0; ch < channels; ch++ {
+			out = append(out, clampSample(float32(d.channels[ch][i])*scale))
+		}
+	}
+	return out, nil
+}
+
+// decodeSubframe 解码一个声道的子帧
+func (d *flacDecoder) decodeSubframe(dst []int64, bps int) error {
+	br := &d.br
+	header, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if header&0x80 != 0 {
+		return fmt.Errorf("%w: 子帧填充位不为 0", ErrInvalidFormat)
+	}
+	typ := int(header >> 1 & 0x3F)
+
+	// wasted bits: 样本低位均为 0 的位数
+	wasted := 0
+	if header&1 != 0 {
+		n, err := br.unary()
+		if err != nil {
+			return err
+		}
+		wasted = int(n) + 1
+		if wasted >= bps {
+			return fmt.Errorf("%w: wasted bits %d 超出位深", ErrInvalidFormat, wasted)
+		}
+		bps -= wasted
+	}
+
+	switch {
+	case typ == 0: // CONSTANT
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		for i := range dst {
+			dst[i] = v
+		}
+	case typ == 1: // VERBATIM
+		for i := range dst {
+			if dst[i], err = br.readSigned(bps); err != nil {
+				return err
+			}
+		}
+	case typ >= 8 && typ <= 12: // FIXED
+		if err := d.decodeFixed(dst, bps, typ&7); err != nil {
+			return err
+		}
+	case typ >= 32: // LPC
+		if err := d.decodeLPC(dst, bps, typ&31+1); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: 保留的子帧类型 %d", ErrInvalidFormat, typ)
+	}
+
+	if wasted > 0 {
+		for i := range dst {
+			dst[i] <<= wasted
+		}
+	}
+	return nil
+}
+
+// decodeFixed 解码固定多项式预测子帧
+func (d *flacDecoder) decodeFixed(dst []int64, bps, order int) error {
+	if order > len(dst) {
+		return fmt.Errorf("%w: 预测阶数 %d 超出块大小", ErrInvalidFormat, order)
+	}
+	var err error
+	for i := 0; i < order; i++ {
+		if dst[i], err = d.br.readSigned(bps); err != nil {
+			return err
+		}
+	}
+	if err := d.decodeResidual(dst, order); err != nil {
+		return err
+	}
+	for i := order; i < len(dst); i++ {
+		switch order {
+		case 1:
+			dst[i] += dst[i-1]
+		case 2:
+			dst[i] += 2*dst[i-1] - dst[i-2]
+		case 3:
+			dst[i] += 3*dst[i-1] - 3*dst[i-2] + dst[i-3]
+		case 4:
+			dst[i] += 4*dst[i-1] - 6*dst[i-2] + 4*dst[i-3] - dst[i-4]
+		}
+	}
+	return nil
+}
+
+// decodeLPC 解码线性预测子帧
+func (d *flacDecoder) decodeLPC(dst []int64, bps, order int) error {
+	if order > len(dst) {
+		return fmt.Errorf("%w: 预测阶数 %d 超出块大小", ErrInvalidFormat, order)
+	}
+	br := &d.br
+	var err error
+	for i := 0; i < order; i++ {
+		if dst[i], err = br.readSigned(bps); err != nil {
+			return err
+		}
+	}
+	precision, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	if precision == 15 {
+		return fmt.Errorf("%w: 无效的系数精度", ErrInvalidFormat)
+	}
+	shift, err := br.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return fmt.Errorf("%w: 负的量化位移 %d", ErrInvalidFormat, shift)
+	}
+	var coefs [32]int64
+	for i := 0; i < order; i++ {
+		if coefs[i], err = br.readSigned(int(precision) + 1); err != nil {
+			return err
+		}
+	}
+	if err := d.decodeResidual(dst, order); err != nil {
+		return err
+	}
+	for i := order; i < len(dst); i++ {
+		var sum int64
+		for j := 0; j < order; j++ {
+			sum += coefs[j] * dst[i-1-j]
+		}
+		dst[i] += sum >> shift
+	}
+	return nil
+}
+
+// decodeResidual 解码 Rice 编码的残差，写入 dst[order:]
+func (d *flacDecoder) decodeResidual(dst []int64, order int) error {
+	br := &d.br
+	method, err := br.read(2)
+	if err != nil {
+		return err
+	}
+	if method > 1 {
+		return fmt.Errorf("%w: 保留的残差编码方式 %d", ErrInvalidFormat, method)
+	}
+	paramBits, escape := uint(4), uint64(15)
+	if method == 1 {
+		paramBits, escape = 5, 31
+	}
+	partitionOrder, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	if len(dst)%partitions != 0 || len(dst)/partitions < order {
+		return fmt.Errorf("%w: 残差分区阶数 %d 无效", ErrInvalidFormat, partitionOrder)
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		end := (p + 1) * len(dst) / partitions
+		param, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+		if param == escape {
+			bits, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for ; i < end; i++ {
+				if bits == 0 {
+					dst[i] = 0
+				} else if dst[i], err = br.readSigned(int(bits)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		for ; i < end; i++ {
+			q, err := br.unary()
+			if err != nil {
+				return err
+			}
+			r, err := br.read(uint(param))
+			if err != nil {
+				return err
+			}
+			u := q<<param | r
+			dst[i] = int64(u>>1) ^ -int64(u&1)
+		}
+	}
+	return nil
+}
+
+// bitReader 大端位读取器
+type bitReader struct {
+	data []byte
+	pos  int // 位偏移
+}
+
+// read 读取 n (<= 64) 位无符号整数
+func (b *bitReader) read(n uint) (uint64, error) {
+	if b.pos+int(n) > len(b.data)*8 {
+		return 0, errFlacEOF
+	}
+	var v uint64
+	for n > 0 {
+		avail := 8 - uint(b.pos&7)
+		take := min(avail, n)
+		bits := uint64(b.data[b.pos>>3]>>(avail-take)) & (1<<take - 1)
+		v = v<<take | bits
+		n -= take
+		b.pos += int(take)
+	}
+	return v, nil
+}
+
+// readSigned 读取 n 位有符号整数
+func (b *bitReader) readSigned(n int) (int64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	v, err := b.read(uint(n))
+	if err != nil {
+		return 0, err
+	}
+	shift := 64 - uint(n)
+	return int64(v<<shift) >> shift, nil
+}
+
+// unary 读取一元编码: 1 之前 0 的个数
+func (b *bitReader) unary() (uint64, error) {
+	var n uint64
+	for {
+		if b.pos >= len(b.data)*8 {
+			return 0, errFlacEOF
+		}
+		// 当前字节剩余位全为 0 时整体跳过
+		off := uint(b.pos & 7)
+		rest := b.data[b.pos>>3] << off
+		if rest == 0 {
+			n += uint64(8 - off)
+			b.pos += int(8 - off)
+			continue
+		}
+		for rest&0x80 == 0 {
+			rest <<= 1
+			n++
+			b.pos++
+		}
+		b.pos++
+		return n, nil
+	}
+}
+
+// skipUTF8 跳过 UTF-8 形式编码的整数 (最长 7 字节)
+func (b *bitReader) skipUTF8() error {
+	first, err := b.read(8)
+	if err != nil {
+		return err
+	}
+	extra := 0
+	for mask := uint64(0x80); first&mask != 0 && mask > 1; mask >>= 1 {
+		extra++
+	}
+	if extra == 1 || extra > 7 {
+		return fmt.Errorf("%w: 帧号编码错误", ErrInvalidFormat)
+	}
+	if extra > 0 {
+		extra--
+	}
+	for ; extra > 0; extra-- {
+		c, err := b.read(8)
+		if err != nil {
+			return err
+		}
+		if c&0xC0 != 0x80 {
+			return fmt.Errorf("%w: 帧号编码错误", ErrInvalidFormat)
+		}
+	}
+	return nil
+}
+
+// align 对齐到下一个字节
+func (b *bitReader) align() {
+	b.pos = (b.pos + 7) &^ 7
+}
@@ -0,0 +1,202 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+)
+
+// bitWriter 测试用大端位写入器
+type bitWriter struct {
+	buf []byte
+	n   int // 已写入的位数
+}
+
+func (w *bitWriter) write(v uint64, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.buf = append(w.buf, 0)
+		}
+		if v>>uint(i)&1 != 0 {
+			w.buf[len(w.buf)-1] |= 0x80 >> uint(w.n%8)
+		}
+		w.n++
+	}
+}
+
+func (w *bitWriter) signed(v int64, bits int) {
+	w.write(uint64(v)&(1<<uint(bits)-1), bits)
+}
+
+func (w *bitWriter) align() {
+	for w.n%8 != 0 {
+		w.write(0, 1)
+	}
+}
+
+// rice 写入单分区 Rice 编码残差，param 为 15 时使用 16 位 escape 编码
+func (w *bitWriter) rice(residual []int64, param int) {
+	w.write(0, 2) // 4 位参数
+	w.write(0, 4) // 分区阶数 0
+	w.write(uint64(param), 4)
+	if param == 15 {
+		w.write(16, 5)
+		for _, e := range residual {
+			w.signed(e, 16)
+		}
+		return
+	}
+	for _, e := range residual {
+		u := uint64(e<<1) ^ uint64(e>>63)
+		for q := u >> uint(param); q > 0; q-- {
+			w.write(0, 1)
+		}
+		w.write(1, 1)
+		w.write(u, param)
+	}
+}
+
+// frameHeader 写入帧头，块大小以 16 位显式编码，采样率与位深取自 STREAMINFO
+func (w *bitWriter) frameHeader(num, blockSize, assignment int) {
+	w.write(0xFFF8, 16)
+	w.write(7, 4) // 块大小编码
+	w.write(0, 4) // 采样率编码
+	w.write(uint64(assignment), 4)
+	w.write(0, 4) // 位深编码 + 保留位
+	w.write(uint64(num), 8)
+	w.write(uint64(blockSize-1), 16)
+	w.write(0, 8) // CRC-8
+}
+
+// buildFlac 构造包含三帧的 16 位双声道 FLAC，覆盖各类子帧与去相关方式
+func buildFlac(left, right []int64) []byte {
+	w := new(bitWriter)
+	w.buf = append(w.buf, "fLaC"...)
+	w.n = 32
+	w.write(1, 1) // 最后一个元数据块
+	w.write(0, 7) // STREAMINFO
+	w.write(34, 24)
+	w.write(4, 16) // 最小块大小
+	w.write(4, 16) // 最大块大小
+	w.write(0, 48) // 帧大小
+	w.write(16000, 20)
+	w.write(1, 3)  // 双声道
+	w.write(15, 5) // 16 位
+	w.write(uint64(len(left)), 36)
+	w.write(0, 64) // MD5
+	w.write(0, 64)
+
+	// 第 1 帧: 独立声道，左声道 VERBATIM，右声道 FIXED 2 阶
+	l, r := left[0:4], right[0:4]
+	w.frameHeader(0, 4, 1)
+	w.write(1<<1, 8)
+	for _, v := range l {
+		w.signed(v, 16)
+	}
+	w.write((8+2)<<1, 8)
+	w.signed(r[0], 16)
+	w.signed(r[1], 16)
+	w.rice([]int64{r[2] - (2*r[1] - r[0]), r[3] - (2*r[2] - r[1])}, 2)
+	w.align()
+	w.write(0, 16)
+
+	// 第 2 帧: mid/side，mid 为 LPC 1 阶，side 为 CONSTANT (17 位)
+	l, r = left[4:8], right[4:8]
+	w.frameHeader(1, 4, 10)
+	mid := make([]int64, 4)
+	for i := range mid {
+		mid[i] = (l[i] + r[i]) >> 1
+	}
+	w.write(32<<1, 8)
+	w.signed(mid[0], 16)
+	w.write(3, 4)  // 系数精度 4 位
+	w.signed(0, 5) // 位移
+	w.signed(1, 4) // 系数
+	w.rice([]int64{mid[1] - mid[0], mid[2] - mid[1], mid[3] - mid[2]}, 3)
+	w.write(0, 8)
+	w.signed(l[0]-r[0], 17)
+	w.align()
+	w.write(0, 16)
+
+	// 第 3 帧: left/side，left 为 2 位 wasted bits 的 FIXED 1 阶 escape 残差，side 为 VERBATIM
+	l, r = left[8:12], right[8:12]
+	w.frameHeader(2, 4, 8)
+	w.write((8+1)<<1|1, 8)
+	w.write(0b01, 2) // wasted bits = 2
+	w.signed(l[0]>>2, 14)
+	w.rice([]int64{(l[1] - l[0]) >> 2, (l[2] - l[1]) >> 2, (l[3] - l[2]) >> 2}, 15)
+	w.write(1<<1, 8)
+	for i := range l {
+		w.signed(l[i]-r[i], 17)
+	}
+	w.align()
+	w.write(0, 16)
+	return w.buf
+}
+
+func TestDecodeFLAC(t *testing.T) {
+	left := []int64{0, 1000, -2000, 32767, 100, 200, 300, 400, -32768, 400, 800, -1200}
+	right := []int64{5, -5, 7, -32768, 98, 198, 298, 398, 32767, -300, 0, 12}
+	data := buildFlac(left, right)
+
+	if f := Sniff(data); f != FormatFLAC {
+		t.Fatalf("sniff: got %q", f)
+	}
+	buf, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.SampleRate != 16000 || buf.Channels != 2 || buf.Frames() != len(left) {
+		t.Fatalf("unexpected buffer: rate %d, channels %d, frames %d", buf.SampleRate, buf.Channels, buf.Frames())
+	}
+	for i := range left {
+		l, r := buf.Data[2*i], buf.Data[2*i+1]
+		if l != float32(left[i])/32768 || r != float32(right[i])/32768 {
+			t.Fatalf("frame %d: got (%v, %v), want (%d, %d)", i, l*32768, r*32768, left[i], right[i])
+		}
+	}
+
+	// 带 ID3v2 标签
+	id3 := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 2, 0, 0}, data...)
+	if buf, err := Decode(id3); err != nil || buf.Frames() != len(left) {
+		t.Fatalf("id3: %v", err)
+	}
+
+	if _, err := DecodeFLAC(data[:len(data)-3]); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("truncated: %v", err)
+	}
+}
+
+func TestSniff(t *testing.T) {
+	cases := []struct {
+		data []byte
+		want Format
+	}{
+		{buildWav("RIFF", fmtChunk(WavFormatPCM, 1, 16000, 16, 0), nil, nil, 0), FormatWAV},
+		{[]byte("fLaC\x80\x00\x00\x22"), FormatFLAC},
+		{[]byte{0xFF, 0xFB, 0x90, 0x64}, FormatMP3},
+		{[]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 0, 0xFF, 0xF3, 0x40}, FormatMP3},
+		{[]byte{0xFF, 0xF1, 0x50, 0x80}, FormatUnknown}, // AAC ADTS
+		{[]byte("OggS"), FormatUnknown},
+	}
+	for _, c := range cases {
+		if got := Sniff(c.data); got != c.want {
+			t.Fatalf("%q: got %q, want %q", c.data, got, c.want)
+		}
+	}
+	if _, err := Decode([]byte("OggS")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("unknown format: %v", err)
+	}
+}
+
+func FuzzDecodeFLAC(f *testing.F) {
+	f.Add(buildFlac([]int64{1, 2, 3, 4, 5, 6, 7, 8, 8, 12, 16, 20}, []int64{0, 0, 0, 0, 3, 4, 5, 6, 0, 0, 0, 0}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		buf, err := DecodeFLAC(data)
+		if err != nil {
+			return
+		}
+		if buf.Channels <= 0 || len(buf.Data)%buf.Channels != 0 {
+			t.Fatalf("invalid buffer: channels %d, samples %d", buf.Channels, len(buf.Data))
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package audio
+
+// G.711 μ-law 与 A-law 编解码，输入输出为 16 位线性 PCM
+
+const (
+	muLawBias = 0x84
+	muLawClip = 32635
+)
+
+// LinearToMuLaw 将 16 位线性 PCM 样本编码为 μ-law
+func LinearToMuLaw(s int16) byte {
+	v := int(s)
+	var sign byte
+	if v < 0 {
+		v = -v
+		sign = 0x80
+	}
+	v = min(v, muLawClip) + muLawBias
+	exp := 7
+	for mask := 0x4000; v&mask == 0 && exp > 0; mask >>= 1 {
+		exp--
+	}
+	mantissa := byte(v>>(exp+3)) & 0x0F
+	return ^(sign | byte(exp)<<4 | mantissa)
+}
+
+// MuLawToLinear 将 μ-law 样本解码为 16 位线性 PCM
+func MuLawToLinear(u byte) int16 {
+	u = ^u
+	t := (int(u&0x0F)<<3 + muLawBias) << (u & 0x70 >> 4)
+	if u&0x80 != 0 {
+		return int16(muLawBias - t)
+	}
+	return int16(t - muLawBias)
+}
+
+// aLawSegmentEnd A-law 各段 13 位幅度的上限
+var aLawSegmentEnd = [8]int{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+
+// LinearToALaw 将 16 位线性 PCM 样本编码为 A-law
+func LinearToALaw(s int16) byte {
+	v := int(s) >> 3
+	mask := byte(0xD5)
+	if v < 0 {
+		mask = 0x55
+		v = -v - 1
+	}
+	seg := 0
+	for seg < len(aLawSegmentEnd) && v > aLawSegmentEnd[seg] {
+		seg++
+	}
+	if seg >= len(aLawSegmentEnd) {
+		return 0x7F ^ mask
+	}
+	a := byte(seg << 4)
+	if seg < 2 {
+		a |= byte(v>>1) & 0x0F
+	} else {
+		a |= byte(v>>seg) & 0x0F
+	}
+	return a ^ mask
+}
+
+// ALawToLinear 将 A-law 样本解码为 16 位线性 PCM
+func ALawToLinear(a byte) int16 {
+	a ^= 0x55
+	t := int(a&0x0F) << 4
+	switch seg := int(a&0x70) >> 4; seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t = (t + 0x108) << (seg - 1)
+	}
+	if a&0x80 != 0 {
+		return int16(t)
+	}
+	return int16(-t)
+}
+
+func decodeMuLaw(src []byte, dst []float32) {
+	for i, b := range src {
+		dst[i] = float32(MuLawToLinear(b)) / 32768
+	}
+}
+
+func decodeALaw(src []byte, dst []float32) {
+	for i, b := range src {
+		dst[i] = float32(ALawToLinear(b)) / 32768
+	}
+}
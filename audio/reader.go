@@ -0,0 +1,107 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Reader 流式样本读取器
+//
+// Read 读取交错存储的 float32 样本，返回的样本数总是声道数的整数倍，读完时返回 io.EOF
+type Reader interface {
+	Read(dst []float32) (int, error)
+	SampleRate() int
+	Channels() int
+}
+
+var (
+	_ Reader = (*WavReader)(nil)
+	_ Reader = (*PCMReader)(nil)
+)
+
+// PCMReader 无头部的裸 PCM 流读取器
+type PCMReader struct {
+	r          io.Reader
+	sampleRate int
+	channels   int
+	frameSize  int // 每帧字节数
+	buf        []byte
+	decode     func(src []byte, dst []float32)
+	err        error
+}
+
+// NewPCMReader 创建裸 PCM 流读取器
+//
+// # Params:
+//
+//	r: 数据源
+//	enc: 样本编码，为空时使用 s16le
+//	sampleRate: 采样率
+//	channels: 声道数
+func NewPCMReader(r io.Reader, enc Encoding, sampleRate, channels int) (*PCMReader, error) {
+	if sampleRate <= 0 {
+		return nil, fmt.Errorf("%w: 采样率 %d", ErrInvalidFormat, sampleRate)
+	}
+	if channels <= 0 || channels > maxChannels {
+		return nil, fmt.Errorf("%w: 声道数 %d", ErrInvalidFormat, channels)
+	}
+	p := &PCMReader{r: r, sampleRate: sampleRate, channels: channels}
+	switch enc {
+	case "", EncodingS16LE:
+		p.decode = decodeS16
+	case EncodingF32LE:
+		p.decode = decodeF32
+	case EncodingMuLaw:
+		p.decode = decodeMuLaw
+	case EncodingALaw:
+		p.decode = decodeALaw
+	default:
+		return nil, fmt.Errorf("%w: 样本编码 %q", ErrUnsupportedFormat, enc)
+	}
+	p.frameSize = enc.BytesPerSample() * channels
+	return p, nil
+}
+
+// SampleRate 返回采样率
+func (p *PCMReader) SampleRate() int {
+	return p.sampleRate
+}
+
+// Channels 返回声道数
+func (p *PCMReader) Channels() int {
+	return p.channels
+}
+
+// Read 读取交错存储的 float32 样本，流结尾不完整的帧会被丢弃
+func (p *PCMReader) Read(dst []float32) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	frames := len(dst) / p.channels
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	want := frames * p.frameSize
+	if cap(p.buf) < want {
+		p.buf = make([]byte, want)
+	}
+	buf := p.buf[:want]
+
+	n, err := io.ReadFull(p.r, buf)
+	n -= n % p.frameSize
+	samples := n / p.frameSize * p.channels
+	p.decode(buf[:n], dst[:samples])
+
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		p.err = io.EOF
+	default:
+		p.err = err
+	}
+	if samples == 0 && p.err != nil {
+		return 0, p.err
+	}
+	return samples, nil
+}
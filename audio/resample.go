@@ -0,0 +1,251 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Quality 重采样质量，质量越高滤波器越长，阻带衰减越大、过渡带越窄
+type Quality int
+
+const (
+	// QualityLow 低质量，适用于实时性要求高的场景
+	QualityLow Quality = iota + 1
+	// QualityMedium 中等质量 (默认)
+	QualityMedium
+	// QualityHigh 高质量，适用于离线处理与音乐
+	QualityHigh
+)
+
+// ParseQuality 解析配置文件中的重采样质量: low, medium, high，空字符串返回 QualityMedium
+func ParseQuality(s string) (Quality, error) {
+	switch strings.ToLower(s) {
+	case "", "medium":
+		return QualityMedium, nil
+	case "low":
+		return QualityLow, nil
+	case "high":
+		return QualityHigh, nil
+	}
+	return 0, fmt.Errorf("不支持的重采样质量: %s", s)
+}
+
+// qualityParams 滤波器参数: 单侧过零点数、Kaiser 窗 beta、截止频率相对 Nyquist 的比例
+var qualityParams = map[Quality]struct {
+	zeroCrossings int
+	beta          float64
+	rolloff       float64
+}{
+	QualityLow:    {8, 5.0, 0.90},
+	QualityMedium: {16, 8.0, 0.94},
+	QualityHigh:   {32, 10.0, 0.97},
+}
+
+// maxCachedPhases 缓存的多相滤波器最大相位数，超过时按需计算
+const maxCachedPhases = 1024
+
+// Resampler 带限 (Kaiser 窗 sinc) 多相重采样器，支持流式处理
+//
+// 输入输出均为单声道样本。转换比例约分为 up/down 后，每个输出样本对应 up 个相位之一，
+// 相位数不超过 maxCachedPhases 时预先计算全部滤波器系数
+type Resampler struct {
+	fromRate, toRate int
+	up, down         int64 // 约分后的插值与抽取倍数
+	half             int   // 单侧滤波器长度 (输入样本数)
+	cutoff           float64
+	beta             float64
+	phases           [][]float32 // 预计算的多相滤波器，nil 时按需计算
+	scratch          []float32
+
+	buf      []float32 // 尚需参与计算的输入样本
+	bufStart int64     // buf[0] 对应的输入样本序号 (可为负，表示起始的零填充)
+	consumed int64     // 已输入的样本总数
+	next     int64     // 下一个输出样本序号
+}
+
+// NewResampler 创建重采样器
+//
+// # Params:
+//
+//	fromRate: 输入采样率
+//	toRate: 输出采样率
+//	quality: 重采样质量，0 表示 QualityMedium
+func NewResampler(fromRate, toRate int, quality Quality) (*Resampler, error) {
+	if fromRate <= 0 || toRate <= 0 {
+		return nil, fmt.Errorf("采样率必须大于 0: %d -> %d", fromRate, toRate)
+	}
+	if quality == 0 {
+		quality = QualityMedium
+	}
+	params, ok := qualityParams[quality]
+	if !ok {
+		return nil, fmt.Errorf("不支持的重采样质量: %d", quality)
+	}
+
+	g := gcd(fromRate, toRate)
+	r := &Resampler{
+		fromRate: fromRate,
+		toRate:   toRate,
+		up:       int64(toRate / g),
+		down:     int64(fromRate / g),
+		beta:     params.beta,
+	}
+	// 降采样时截止频率降低到输出 Nyquist 以下，滤波器按比例加长
+	r.cutoff = params.rolloff * min(1, float64(toRate)/float64(fromRate))
+	r.half = int(math.Ceil(float64(params.zeroCrossings) / r.cutoff))
+
+	if r.up <= maxCachedPhases {
+		r.phases = make([][]float32, r.up)
+		for p := range r.phases {
+			r.phases[p] = r.kernel(float64(p)/float64(r.up), nil)
+		}
+	} else {
+		r.scratch = make([]float32, 2*r.half)
+	}
+	r.Reset()
+	return r, nil
+}
+
+// Reset 清空内部状态，用于处理新的音频流
+func (r *Resampler) Reset() {
+	// 起始处补 half 个零，使第一个输出样本对齐第一个输入样本
+	r.buf = make([]float32, r.half, r.half+4096)
+	r.bufStart = -int64(r.half)
+	r.consumed = 0
+	r.next = 0
+}
+
+// Process 输入一段样本，返回当前可以计算的输出样本
+//
+// 由于滤波器需要后续样本，输出相对输入有 half 个样本的延迟，流结束时调用 Flush 取出剩余样本
+func (r *Resampler) Process(in []float32) []float32 {
+	if r.up == r.down {
+		r.consumed += int64(len(in))
+		return append([]float32(nil), in...)
+	}
+	r.buf = append(r.buf, in...)
+	r.consumed += int64(len(in))
+	return r.drain(nil, r.consumed)
+}
+
+// Flush 结束输入并返回剩余的输出样本，之后可以继续用于新的音频流
+func (r *Resampler) Flush() []float32 {
+	if r.up == r.down {
+		r.Reset()
+		return nil
+	}
+	// 末尾补零，输出总长度为 ceil(输入长度 * toRate / fromRate)
+	total := r.consumed
+	r.buf = append(r.buf, make([]float32, r.half+1)...)
+	out := r.drain(nil, total)
+	r.Reset()
+	return out
+}
+
+// drain 计算所有输入已就绪且对应输入时刻小于 limit 的输出样本
+func (r *Resampler) drain(out []float32, limit int64) []float32 {
+	available := r.bufStart + int64(len(r.buf))
+	for {
+		// 输出样本 n 对应输入时刻 n * down / up = base + phase / up
+		pos := r.next * r.down
+		base := pos / r.up
+		if base >= limit || base+int64(r.half) >= available {
+			break
+		}
+		phase := pos % r.up
+
+		var weights []float32
+		if r.phases != nil {
+			weights = r.phases[phase]
+		} else {
+			weights = r.kernel(float64(phase)/float64(r.up), r.scratch)
+		}
+
+		// 参与计算的输入样本: base-half+1 ... base+half
+		start := int(base - int64(r.half) + 1 - r.bufStart)
+		var sum float32
+		for i, w := range weights {
+			sum += w * r.buf[start+i]
+		}
+		out = append(out, sum)
+		r.next++
+	}
+
+	// 丢弃不再需要的样本
+	keepFrom := (r.next*r.down)/r.up - int64(r.half) + 1
+	if drop := int(keepFrom - r.bufStart); drop > 0 && drop <= len(r.buf) {
+		n := copy(r.buf, r.buf[drop:])
+		r.buf = r.buf[:n]
+		r.bufStart = keepFrom
+	}
+	return out
+}
+
+// kernel 计算小数偏移 frac 处的滤波器系数，长度为 2*half
+func (r *Resampler) kernel(frac float64, dst []float32) []float32 {
+	if dst == nil {
+		dst = make([]float32, 2*r.half)
+	}
+	for i := range dst {
+		// 输入样本 base-half+1+i 到输出时刻的距离
+		x := frac + float64(r.half-1-i)
+		dst[i] = float32(r.cutoff * sinc(r.cutoff*x) * kaiser(x/float64(r.half), r.beta))
+	}
+	return dst
+}
+
+// Resample 使用 QualityMedium 将单声道样本从 fromRate 转换为 toRate
+func Resample(samples []float32, fromRate, toRate int) []float32 {
+	return ResampleQuality(samples, fromRate, toRate, QualityMedium)
+}
+
+// ResampleQuality 使用指定质量将单声道样本从 fromRate 转换为 toRate，参数无效时原样返回
+func ResampleQuality(samples []float32, fromRate, toRate int, quality Quality) []float32 {
+	if fromRate == toRate || len(samples) == 0 {
+		return samples
+	}
+	r, err := NewResampler(fromRate, toRate, quality)
+	if err != nil {
+		return samples
+	}
+	out := r.Process(samples)
+	return append(out, r.Flush()...)
+}
+
+// sinc 归一化 sinc 函数 sin(πx)/(πx)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	px := math.Pi * x
+	return math.Sin(px) / px
+}
+
+// kaiser Kaiser 窗，x 取值范围 [-1, 1]
+func kaiser(x, beta float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return besselI0(beta*math.Sqrt(1-x*x)) / besselI0(beta)
+}
+
+// besselI0 第一类零阶修正贝塞尔函数
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+// sine 生成指定频率的正弦波
+func sine(freq float64, rate, n int) []float32 {
+	out := make([]float32, n)
+	for i := range out {
+		out[i] = float32(0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(rate)))
+	}
+	return out
+}
+
+// rms 计算 [from, to) 区间的均方根
+func rms(data []float32, from, to int) float64 {
+	var sum float64
+	for _, v := range data[from:to] {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum / float64(to-from))
+}
+
+func TestResample(t *testing.T) {
+	cases := []struct {
+		from, to int
+		quality  Quality
+	}{
+		{44100, 16000, QualityLow},
+		{16000, 44100, QualityMedium},
+		{48000, 16000, QualityHigh},
+		{22050, 24000, QualityMedium},
+	}
+	for _, c := range cases {
+		in := sine(1000, c.from, c.from/2)
+		out := ResampleQuality(in, c.from, c.to, c.quality)
+		if want := (len(in)*c.to + c.from - 1) / c.from; len(out) != want {
+			t.Fatalf("%d -> %d: got %d samples, want %d", c.from, c.to, len(out), want)
+		}
+
+		// 与理想正弦波比较，忽略两端的滤波器过渡区
+		want := sine(1000, c.to, len(out))
+		var maxErr float64
+		for i := len(out) / 10; i < len(out)*9/10; i++ {
+			maxErr = max(maxErr, math.Abs(float64(out[i]-want[i])))
+		}
+		if maxErr > 0.01 {
+			t.Fatalf("%d -> %d: max error %f", c.from, c.to, maxErr)
+		}
+	}
+}
+
+func TestResampleAntiAliasing(t *testing.T) {
+	// 12KHz 超过 16KHz 的 Nyquist 频率，降采样后应被滤除
+	in := sine(12000, 48000, 48000)
+	out := Resample(in, 48000, 16000)
+	if level := rms(out, 1000, len(out)-1000); level > 0.005 {
+		t.Fatalf("aliased tone not attenuated: rms %f", level)
+	}
+}
+
+func TestResamplerStreaming(t *testing.T) {
+	in := sine(440, 44100, 20000)
+	want := Resample(in, 44100, 16000)
+
+	r, err := NewResampler(44100, 16000, QualityMedium)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for round := 0; round < 2; round++ {
+		var got []float32
+		for i := 0; i < len(in); i += 777 {
+			got = append(got, r.Process(in[i:min(i+777, len(in))])...)
+		}
+		got = append(got, r.Flush()...)
+		if len(got) != len(want) {
+			t.Fatalf("round %d: got %d samples, want %d", round, len(got), len(want))
+		}
+		for i := range want {
+			if math.Abs(float64(got[i]-want[i])) > 1e-6 {
+				t.Fatalf("round %d: sample %d: got %f, want %f", round, i, got[i], want[i])
+			}
+		}
+	}
+
+	if _, err := NewResampler(0, 16000, QualityMedium); err == nil {
+		t.Fatal("expected error for invalid sample rate")
+	}
+	if _, err := ParseQuality("best"); err == nil {
+		t.Fatal("expected error for invalid quality")
+	}
+}
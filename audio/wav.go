@@ -0,0 +1,382 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WAV 编码格式 (wFormatTag)
+const (
+	WavFormatPCM        = 0x0001
+	WavFormatIEEEFloat  = 0x0003
+	WavFormatALaw       = 0x0006
+	WavFormatMuLaw      = 0x0007
+	WavFormatExtensible = 0xFFFE
+)
+
+// maxChannels 允许的最大声道数，防止异常头部导致过大的内存分配
+const maxChannels = 64
+
+// WavFormat WAV 的 fmt 块信息
+type WavFormat struct {
+	FormatTag     uint16 // 编码格式，EXTENSIBLE 已解析为实际的子格式
+	Channels      int    // 声道数
+	SampleRate    int    // 采样率
+	BitsPerSample int    // 每个样本占用的位数 (容器大小)
+	ValidBits     int    // 有效位数，仅 EXTENSIBLE 格式可能小于 BitsPerSample
+	BlockAlign    int    // 每帧字节数
+}
+
+// WavReader 流式 WAV 读取器
+//
+// 逐块遍历 RIFF / RF64 结构，跳过 LIST、fact 等无关块，支持 8/16/24/32 位整数、32/64 位浮点 PCM、
+// G.711 A-law / μ-law，以及 EXTENSIBLE 头部。读取时仅缓存一次 Read 所需的数据
+type WavReader struct {
+	r         io.Reader
+	format    WavFormat
+	remaining int64 // data 块剩余字节数，-1 表示未知 (读到 EOF 为止)
+	declared  int64 // data 块声明的字节数
+	buf       []byte
+	decode    func(src []byte, dst []float32)
+	err       error
+}
+
+// NewWavReader 读取 WAV 头部，返回定位到 data 块起始处的读取器
+func NewWavReader(r io.Reader) (*WavReader, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, truncated("RIFF 头部", err)
+	}
+	riff := string(header[0:4])
+	if (riff != "RIFF" && riff != "RF64" && riff != "BW64") || string(header[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: 不是 WAV 文件", ErrInvalidFormat)
+	}
+	isRF64 := riff != "RIFF"
+
+	w := &WavReader{r: r}
+	var haveFormat bool
+	var ds64DataSize int64 = -1
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, fmt.Errorf("%w: 未找到 data 块", ErrInvalidFormat)
+			}
+			return nil, truncated("块头部", err)
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "ds64":
+			body, err := readChunk(r, size, "ds64 块")
+			if err != nil {
+				return nil, err
+			}
+			if len(body) < 24 {
+				return nil, fmt.Errorf("%w: ds64 块长度 %d 小于 24", ErrInvalidFormat, len(body))
+			}
+			ds64DataSize = int64(binary.LittleEndian.Uint64(body[8:16]))
+		case "fmt ":
+			body, err := readChunk(r, size, "fmt 块")
+			if err != nil {
+				return nil, err
+			}
+			if w.format, err = parseWavFormat(body); err != nil {
+				return nil, err
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, fmt.Errorf("%w: data 块位于 fmt 块之前", ErrInvalidFormat)
+			}
+			switch {
+			case isRF64 && size == math.MaxUint32 && ds64DataSize >= 0:
+				size = ds64DataSize
+			case size == 0 || size == math.MaxUint32:
+				// 流式写入的 WAV 可能没有回填长度
+				size = -1
+			}
+			w.remaining = size
+			w.declared = size
+			if err := w.setup(); err != nil {
+				return nil, err
+			}
+			return w, nil
+		default:
+			// 跳过 LIST、fact、cue 等块
+			if err := skipChunk(r, size); err != nil {
+				return nil, truncated(fmt.Sprintf("%q 块", id), err)
+			}
+		}
+	}
+}
+
+// parseWavFormat 解析 fmt 块
+func parseWavFormat(body []byte) (WavFormat, error) {
+	if len(body) < 16 {
+		return WavFormat{}, fmt.Errorf("%w: fmt 块长度 %d 小于 16", ErrInvalidFormat, len(body))
+	}
+	f := WavFormat{
+		FormatTag:     binary.LittleEndian.Uint16(body[0:2]),
+		Channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+		SampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+		BlockAlign:    int(binary.LittleEndian.Uint16(body[12:14])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+	}
+	f.ValidBits = f.BitsPerSample
+
+	if f.FormatTag == WavFormatExtensible {
+		// cbSize(2) + wValidBitsPerSample(2) + dwChannelMask(4) + SubFormat GUID(16)
+		if len(body) < 40 {
+			return WavFormat{}, fmt.Errorf("%w: EXTENSIBLE fmt 块长度 %d 小于 40", ErrInvalidFormat, len(body))
+		}
+		if valid := int(binary.LittleEndian.Uint16(body[18:20])); valid > 0 {
+			f.ValidBits = valid
+		}
+		// GUID 的前两个字节即为实际的编码格式
+		f.FormatTag = binary.LittleEndian.Uint16(body[24:26])
+	}
+
+	if f.Channels <= 0 || f.Channels > maxChannels {
+		return WavFormat{}, fmt.Errorf("%w: 声道数 %d", ErrInvalidFormat, f.Channels)
+	}
+	if f.SampleRate <= 0 {
+		return WavFormat{}, fmt.Errorf("%w: 采样率 %d", ErrInvalidFormat, f.SampleRate)
+	}
+	if f.BitsPerSample <= 0 || f.BitsPerSample%8 != 0 {
+		return WavFormat{}, fmt.Errorf("%w: 采样位数 %d", ErrUnsupportedFormat, f.BitsPerSample)
+	}
+	if want := f.Channels * f.BitsPerSample / 8; f.BlockAlign != want {
+		// 部分编码器写入错误的 BlockAlign，以声道数与采样位数为准
+		f.BlockAlign = want
+	}
+	return f, nil
+}
+
+// setup 根据格式选择样本解码函数
+func (w *WavReader) setup() error {
+	f := w.format
+	switch {
+	case f.FormatTag == WavFormatPCM && f.BitsPerSample == 8:
+		w.decode = decodeU8
+	case f.FormatTag == WavFormatPCM && f.BitsPerSample == 16:
+		w.decode = decodeS16
+	case f.FormatTag == WavFormatPCM && f.BitsPerSample == 24:
+		w.decode = decodeS24
+	case f.FormatTag == WavFormatPCM && f.BitsPerSample == 32:
+		w.decode = decodeS32
+	case f.FormatTag == WavFormatIEEEFloat && f.BitsPerSample == 32:
+		w.decode = decodeF32
+	case f.FormatTag == WavFormatIEEEFloat && f.BitsPerSample == 64:
+		w.decode = decodeF64
+	case f.FormatTag == WavFormatALaw && f.BitsPerSample == 8:
+		w.decode = decodeALaw
+	case f.FormatTag == WavFormatMuLaw && f.BitsPerSample == 8:
+		w.decode = decodeMuLaw
+	default:
+		return fmt.Errorf("%w: 编码格式 0x%04X, 采样位数 %d", ErrUnsupportedFormat, f.FormatTag, f.BitsPerSample)
+	}
+	return nil
+}
+
+// Format 返回 fmt 块信息
+func (w *WavReader) Format() WavFormat {
+	return w.format
+}
+
+// SampleRate 返回采样率
+func (w *WavReader) SampleRate() int {
+	return w.format.SampleRate
+}
+
+// Channels 返回声道数
+func (w *WavReader) Channels() int {
+	return w.format.Channels
+}
+
+// Read 读取交错存储的 float32 样本，返回的样本数总是声道数的整数倍
+//
+// 读到 data 块结尾时返回 io.EOF；文件在声明的 data 长度之前结束时，先返回已读取的完整帧，之后返回 ErrTruncated
+func (w *WavReader) Read(dst []float32) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	frames := len(dst) / w.format.Channels
+	if frames == 0 {
+		return 0, io.ErrShortBuffer
+	}
+	want := int64(frames * w.format.BlockAlign)
+	if w.remaining >= 0 {
+		if w.remaining == 0 {
+			w.err = io.EOF
+			return 0, io.EOF
+		}
+		want = min(want, w.remaining)
+	}
+	if int64(cap(w.buf)) < want {
+		w.buf = make([]byte, want)
+	}
+	buf := w.buf[:want]
+
+	n, err := io.ReadFull(w.r, buf)
+	if w.remaining >= 0 {
+		w.remaining -= int64(n)
+	}
+	// 只解码完整的帧
+	n -= n % w.format.BlockAlign
+	samples := n / (w.format.BlockAlign / w.format.Channels)
+	w.decode(buf[:n], dst[:samples])
+
+	switch {
+	case err == nil:
+	case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		if w.remaining > 0 {
+			w.err = fmt.Errorf("%w: data 块声明 %d 字节, 实际只有 %d 字节", ErrTruncated, w.declared, w.declared-w.remaining)
+		} else {
+			// 长度未知或最后一帧不完整
+			w.err = io.EOF
+		}
+	default:
+		w.err = err
+	}
+	if samples == 0 && w.err != nil {
+		return 0, w.err
+	}
+	return samples, nil
+}
+
+// ReadAll 读取剩余的全部样本
+func (w *WavReader) ReadAll() ([]float32, error) {
+	var out []float32
+	if w.remaining > 0 {
+		// 预分配以声明长度为上限，避免异常头部导致过大的内存分配
+		out = make([]float32, 0, min(w.remaining, 1<<24)/int64(w.format.BlockAlign/w.format.Channels))
+	}
+	chunk := make([]float32, 4096*w.format.Channels)
+	for {
+		n, err := w.Read(chunk)
+		out = append(out, chunk[:n]...)
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// DecodeWAV 解码 WAV 字节流
+func DecodeWAV(data []byte) (*Buffer, error) {
+	w, err := NewWavReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	samples, err := w.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return &Buffer{SampleRate: w.SampleRate(), Channels: w.Channels(), Data: samples}, nil
+}
+
+// readChunk 读取块内容 (包括奇数长度块的填充字节)
+func readChunk(r io.Reader, size int64, name string) ([]byte, error) {
+	if size > 1<<20 {
+		return nil, fmt.Errorf("%w: %s长度 %d 过大", ErrInvalidFormat, name, size)
+	}
+	body := make([]byte, size+size%2)
+	n, err := io.ReadFull(r, body)
+	// 文件末尾的奇数长度块可能缺少填充字节
+	if err != nil && int64(n) < size {
+		return nil, truncated(name, err)
+	}
+	return body[:size], nil
+}
+
+// skipChunk 跳过块内容 (包括奇数长度块的填充字节)
+func skipChunk(r io.Reader, size int64) error {
+	size += size % 2
+	if s, ok := r.(io.Seeker); ok {
+		cur, err := s.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		end, err := s.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if cur+size > end {
+			return io.ErrUnexpectedEOF
+		}
+		_, err = s.Seek(cur+size, io.SeekStart)
+		return err
+	}
+	n, err := io.CopyN(io.Discard, r, size)
+	if err != nil && n < size {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// truncated 构造数据不完整的错误
+func truncated(what string, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: 读取%s时文件提前结束", ErrTruncated, what)
+	}
+	return fmt.Errorf("读取%s失败: %w", what, err)
+}
+
+func decodeU8(src []byte, dst []float32) {
+	for i, b := range src {
+		dst[i] = (float32(b) - 128) / 128
+	}
+}
+
+func decodeS16(src []byte, dst []float32) {
+	for i := range dst {
+		dst[i] = float32(int16(binary.LittleEndian.Uint16(src[i*2:]))) / 32768
+	}
+}
+
+func decodeS24(src []byte, dst []float32) {
+	for i := range dst {
+		b := src[i*3:]
+		v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+		dst[i] = float32(v) / (1 << 23)
+	}
+}
+
+func decodeS32(src []byte, dst []float32) {
+	for i := range dst {
+		dst[i] = float32(float64(int32(binary.LittleEndian.Uint32(src[i*4:]))) / (1 << 31))
+	}
+}
+
+func decodeF32(src []byte, dst []float32) {
+	for i := range dst {
+		dst[i] = clampSample(math.Float32frombits(binary.LittleEndian.Uint32(src[i*4:])))
+	}
+}
+
+func decodeF64(src []byte, dst []float32) {
+	for i := range dst {
+		dst[i] = clampSample(float32(math.Float64frombits(binary.LittleEndian.Uint64(src[i*8:]))))
+	}
+}
+
+// clampSample 将样本限制在 [-1, 1]，NaN 视为 0
+func clampSample(v float32) float32 {
+	switch {
+	case v != v:
+		return 0
+	case v > 1:
+		return 1
+	case v < -1:
+		return -1
+	}
+	return v
+}
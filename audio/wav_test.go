@@ -0,0 +1,153 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+// buildWav 构造测试用 WAV，extra 为插入在 fmt 与 data 之间的块
+func buildWav(riff string, fmtBody []byte, extra []byte, data []byte, dataSize uint32) []byte {
+	var b bytes.Buffer
+	b.WriteString(riff)
+	_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+	b.WriteString("WAVE")
+	b.WriteString("fmt ")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(len(fmtBody)))
+	b.Write(fmtBody)
+	b.Write(extra)
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, dataSize)
+	b.Write(data)
+	return b.Bytes()
+}
+
+// fmtChunk 构造 fmt 块，subFormat 非 0 时使用 EXTENSIBLE 头部
+func fmtChunk(tag uint16, channels, rate, bits int, subFormat uint16) []byte {
+	var b bytes.Buffer
+	if subFormat != 0 {
+		tag = WavFormatExtensible
+	}
+	_ = binary.Write(&b, binary.LittleEndian, tag)
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(rate))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(rate*channels*bits/8))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels*bits/8))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(bits))
+	if subFormat != 0 {
+		_ = binary.Write(&b, binary.LittleEndian, uint16(22))
+		_ = binary.Write(&b, binary.LittleEndian, uint16(bits))
+		_ = binary.Write(&b, binary.LittleEndian, uint32(0))
+		_ = binary.Write(&b, binary.LittleEndian, subFormat)
+		b.Write([]byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71})
+	}
+	return b.Bytes()
+}
+
+func TestDecodeWAV(t *testing.T) {
+	list := append([]byte("LIST"), 5, 0, 0, 0, 'I', 'N', 'F', 'O', 'x', 0) // 奇数长度块带填充字节
+	fact := append([]byte("fact"), 4, 0, 0, 0, 2, 0, 0, 0)
+
+	f32 := make([]byte, 8)
+	binary.LittleEndian.PutUint32(f32, math.Float32bits(0.5))
+	binary.LittleEndian.PutUint32(f32[4:], math.Float32bits(-0.25))
+
+	cases := []struct {
+		name string
+		wav  []byte
+		want []float32
+	}{
+		{"u8", buildWav("RIFF", fmtChunk(WavFormatPCM, 1, 8000, 8, 0), nil, []byte{128, 192, 0}, 3), []float32{0, 0.5, -1}},
+		{"s16 list", buildWav("RIFF", fmtChunk(WavFormatPCM, 1, 16000, 16, 0), list, []byte{0x00, 0x40, 0x00, 0x80}, 4), []float32{0.5, -1}},
+		{"s24 extensible", buildWav("RIFF", fmtChunk(0, 1, 48000, 24, WavFormatPCM), nil, []byte{0, 0, 0x40, 0, 0, 0xC0}, 6), []float32{0.5, -0.5}},
+		{"s32", buildWav("RIFF", fmtChunk(WavFormatPCM, 1, 16000, 32, 0), nil, []byte{0, 0, 0, 0x40, 0, 0, 0, 0xC0}, 8), []float32{0.5, -0.5}},
+		{"f32 fact", buildWav("RIFF", fmtChunk(WavFormatIEEEFloat, 2, 16000, 32, 0), fact, f32, 8), []float32{0.5, -0.25}},
+		{"rf64 unknown size", buildWav("RF64", fmtChunk(WavFormatPCM, 1, 16000, 16, 0), nil, []byte{0x00, 0x40}, math.MaxUint32), []float32{0.5}},
+	}
+	for _, c := range cases {
+		buf, err := DecodeWAV(c.wav)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if len(buf.Data) != len(c.want) {
+			t.Fatalf("%s: got %v, want %v", c.name, buf.Data, c.want)
+		}
+		for i := range c.want {
+			if math.Abs(float64(buf.Data[i]-c.want[i])) > 1e-6 {
+				t.Fatalf("%s: got %v, want %v", c.name, buf.Data, c.want)
+			}
+		}
+	}
+
+	stereo, _ := DecodeWAV(cases[4].wav)
+	if stereo.Channels != 2 || stereo.Frames() != 1 || stereo.Mono()[0] != 0.125 {
+		t.Fatalf("unexpected stereo buffer %+v", stereo)
+	}
+}
+
+func TestDecodeWAVErrors(t *testing.T) {
+	good := buildWav("RIFF", fmtChunk(WavFormatPCM, 1, 16000, 16, 0), nil, []byte{0, 0, 0, 0}, 4)
+
+	if _, err := DecodeWAV(good[:len(good)-1]); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("truncated data: %v", err)
+	}
+	if _, err := DecodeWAV(good[:20]); !errors.Is(err, ErrTruncated) {
+		t.Fatalf("truncated fmt: %v", err)
+	}
+	if _, err := DecodeWAV([]byte("not a wav file")); !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("invalid header: %v", err)
+	}
+	adpcm := buildWav("RIFF", fmtChunk(0x0011, 1, 16000, 16, 0), nil, []byte{0, 0}, 2)
+	if _, err := DecodeWAV(adpcm); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("unsupported format: %v", err)
+	}
+}
+
+func FuzzDecodeWAV(f *testing.F) {
+	f.Add(buildWav("RIFF", fmtChunk(WavFormatPCM, 1, 16000, 16, 0), nil, []byte{0, 0x40, 0, 0x80}, 4))
+	f.Add(buildWav("RIFF", fmtChunk(0, 2, 44100, 24, WavFormatPCM), []byte("LIST\x00\x00\x00\x00"), make([]byte, 12), 12))
+	f.Add(buildWav("RF64", fmtChunk(WavFormatIEEEFloat, 1, 8000, 64, 0), nil, make([]byte, 16), math.MaxUint32))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		buf, err := DecodeWAV(data)
+		if err != nil {
+			return
+		}
+		if buf.Channels <= 0 || len(buf.Data)%buf.Channels != 0 {
+			t.Fatalf("invalid buffer: channels %d, samples %d", buf.Channels, len(buf.Data))
+		}
+		for _, v := range buf.Data {
+			if v < -1 || v > 1 || v != v {
+				t.Fatalf("sample out of range: %v", v)
+			}
+		}
+	})
+}
+
+func TestDecodeMono(t *testing.T) {
+	data := make([]byte, 0, 800*2*2)
+	for i := range 800 {
+		data = binary.LittleEndian.AppendUint16(data, uint16(int16(i)))
+		data = binary.LittleEndian.AppendUint16(data, uint16(int16(-i)))
+	}
+	wav := buildWav("RIFF", fmtChunk(WavFormatPCM, 2, 8000, 16, 0), nil, data, uint32(len(data)))
+
+	samples, err := DecodeMono(wav, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 双声道互为相反数，混合后为静音，8KHz 重采样到 16KHz 后样本数加倍
+	if len(samples) != 1600 {
+		t.Fatalf("samples: %d", len(samples))
+	}
+	for i, v := range samples {
+		if math.Abs(float64(v)) > 1e-6 {
+			t.Fatalf("sample %d: %v", i, v)
+		}
+	}
+
+	if _, err := DecodeMono([]byte("not audio"), 16000); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,257 @@
+package speech
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFileName 模型包清单文件名
+const ManifestFileName = "manifest.json"
+
+// Manifest 模型包清单 (manifest.json)
+//
+// # Examples:
+//
+//	{
+//	  "engine": "whisper",
+//	  "name": "whisper-small",
+//	  "files": {
+//	    "encoder": {"path": "small_encoder_model.onnx", "sha256": "..."},
+//	    "decoder": {"path": "small_decoder_model_merged.onnx", "sha256": "..."}
+//	  },
+//	  "params": {"model_layers": 12, "num_heads": 12},
+//	  "signature": "base64(ed25519)"
+//	}
+type Manifest struct {
+	Engine    string                `json:"engine"`              // 引擎类型，例如："paraformer", "whisper"
+	Name      string                `json:"name,omitempty"`      // 模型名称
+	Version   string                `json:"version,omitempty"`   // 模型版本
+	Files     map[string]BundleFile `json:"files"`               // 文件角色 -> 文件，例如："model", "tokens"
+	Params    map[string]any        `json:"params,omitempty"`    // 模型超参数，例如："model_layers"
+	Signature string                `json:"signature,omitempty"` // 对清单 (不含签名) 的 Ed25519 签名，Base64 编码
+
+	dir string
+}
+
+// BundleFile 模型包中的文件
+type BundleFile struct {
+	Path   string `json:"path"`   // 相对于模型包目录的路径
+	SHA256 string `json:"sha256"` // 文件 SHA-256，十六进制编码
+}
+
+// OpenBundle 读取并校验模型包
+//
+// # Params:
+//
+//	dir: 模型包目录
+//	engine: 期望的引擎类型
+//	publicKey: (可选) Ed25519 公钥，非空时要求清单签名有效
+func OpenBundle(dir, engine string, publicKey ed25519.PublicKey) (*Manifest, error) {
+	m, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if m.Engine != engine {
+		return nil, fmt.Errorf("模型包引擎类型不匹配: 期望 %s, 实际 %s", engine, m.Engine)
+	}
+	if err := m.checkPaths(); err != nil {
+		return nil, err
+	}
+	if publicKey != nil {
+		if err := m.VerifySignature(publicKey); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.VerifyFiles(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParsePublicKey 解析 Base64 编码的 Ed25519 公钥，空字符串返回 nil
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("公钥格式错误: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("公钥长度错误: 期望 %d 字节, 实际 %d 字节", ed25519.PublicKeySize, len(key))
+	}
+	return key, nil
+}
+
+// ReadManifest 读取模型包目录下的 manifest.json，不做校验
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFileName))
+	if err != nil {
+		return nil, fmt.Errorf("无法读取模型包清单: %w", err)
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("解析模型包清单失败: %w", err)
+	}
+	m.dir = dir
+	return m, nil
+}
+
+// NewManifest 根据模型包目录中的文件生成清单，自动计算文件的 SHA-256
+//
+// # Params:
+//
+//	dir: 模型包目录
+//	engine: 引擎类型
+//	files: 文件角色 -> 相对路径
+//	params: 模型超参数
+func NewManifest(dir, engine string, files map[string]string, params map[string]any) (*Manifest, error) {
+	m := &Manifest{
+		Engine: engine,
+		Files:  make(map[string]BundleFile, len(files)),
+		Params: params,
+		dir:    dir,
+	}
+	for role, path := range files {
+		sum, err := fileSHA256(filepath.Join(dir, path))
+		if err != nil {
+			return nil, fmt.Errorf("计算文件 %s 的 SHA-256 失败: %w", path, err)
+		}
+		m.Files[role] = BundleFile{Path: filepath.ToSlash(path), SHA256: sum}
+	}
+	return m, nil
+}
+
+// Save 将清单写入模型包目录
+func (m *Manifest) Save() error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(m.dir, ManifestFileName), data, 0o644)
+}
+
+// Dir 返回模型包目录
+func (m *Manifest) Dir() string {
+	return m.dir
+}
+
+// Path 返回指定角色文件的完整路径，不存在时返回空字符串
+func (m *Manifest) Path(role string) string {
+	f, ok := m.Files[role]
+	if !ok || f.Path == "" {
+		return ""
+	}
+	return filepath.Join(m.dir, filepath.FromSlash(f.Path))
+}
+
+// Require 检查清单中包含所需的文件角色
+func (m *Manifest) Require(roles ...string) error {
+	var missing []string
+	for _, role := range roles {
+		if m.Path(role) == "" {
+			missing = append(missing, role)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("模型包缺少文件: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Int 读取整数类型的超参数
+func (m *Manifest) Int(name string) (int, bool) {
+	switch v := m.Params[name].(type) {
+	case float64:
+		return int(v), v == float64(int(v))
+	case int:
+		return v, true
+	}
+	return 0, false
+}
+
+// checkPaths 检查文件路径位于模型包目录内，拒绝绝对路径与跳出目录的 ".."
+func (m *Manifest) checkPaths() error {
+	for role, f := range m.Files {
+		if !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+			return fmt.Errorf("文件 %s 的路径 %q 不在模型包目录内", role, f.Path)
+		}
+	}
+	return nil
+}
+
+// VerifyFiles 校验清单中所有文件的 SHA-256
+func (m *Manifest) VerifyFiles() error {
+	for role, f := range m.Files {
+		if f.SHA256 == "" {
+			return fmt.Errorf("文件 %s (%s) 缺少 SHA-256", role, f.Path)
+		}
+		sum, err := fileSHA256(m.Path(role))
+		if err != nil {
+			return fmt.Errorf("读取文件 %s (%s) 失败: %w", role, f.Path, err)
+		}
+		if !strings.EqualFold(sum, f.SHA256) {
+			return fmt.Errorf("文件 %s (%s) SHA-256 不匹配: 期望 %s, 实际 %s", role, f.Path, f.SHA256, sum)
+		}
+	}
+	return nil
+}
+
+// Sign 使用 Ed25519 私钥对清单签名
+func (m *Manifest) Sign(privateKey ed25519.PrivateKey) error {
+	payload, err := m.signingPayload()
+	if err != nil {
+		return err
+	}
+	m.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, payload))
+	return nil
+}
+
+// VerifySignature 使用 Ed25519 公钥校验清单签名
+func (m *Manifest) VerifySignature(publicKey ed25519.PublicKey) error {
+	if m.Signature == "" {
+		return fmt.Errorf("模型包清单未签名")
+	}
+	sig, err := base64.StdEncoding.DecodeString(m.Signature)
+	if err != nil {
+		return fmt.Errorf("模型包签名格式错误: %w", err)
+	}
+	payload, err := m.signingPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(publicKey, payload, sig) {
+		return fmt.Errorf("模型包签名校验失败")
+	}
+	return nil
+}
+
+// signingPayload 签名内容: 不含签名字段的清单 JSON (map 按键排序，结果稳定)
+func (m *Manifest) signingPayload() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = ""
+	return json.Marshal(&unsigned)
+}
+
+// fileSHA256 计算文件 SHA-256
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
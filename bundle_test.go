@@ -0,0 +1,84 @@
+package speech
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx"), []byte("model"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tokens.txt"), []byte("tokens"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManifest(dir, "paraformer", map[string]string{
+		"model":  "model.onnx",
+		"tokens": "tokens.txt",
+	}, map[string]any{"model_layers": 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Sign(privateKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err = OpenBundle(dir, "paraformer", publicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layers, ok := m.Int("model_layers"); !ok || layers != 12 {
+		t.Fatalf("model_layers = %d, %v", layers, ok)
+	}
+	if m.Path("model") != filepath.Join(dir, "model.onnx") {
+		t.Fatalf("unexpected model path %s", m.Path("model"))
+	}
+	if err := m.Require("model", "cmvn"); err == nil {
+		t.Fatal("expected missing cmvn error")
+	}
+
+	if _, err := OpenBundle(dir, "whisper", nil); err == nil {
+		t.Fatal("expected engine mismatch error")
+	}
+
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+	if _, err := OpenBundle(dir, "paraformer", otherKey); err == nil {
+		t.Fatal("expected signature error")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "tokens.txt"), []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenBundle(dir, "paraformer", publicKey); err == nil {
+		t.Fatal("expected checksum error")
+	}
+}
+
+func TestBundlePathContainment(t *testing.T) {
+	for _, path := range []string{"../secret.onnx", "a/../../secret.onnx", "/etc/passwd", ""} {
+		dir := t.TempDir()
+		m := &Manifest{
+			Engine: "paraformer",
+			Files:  map[string]BundleFile{"model": {Path: path, SHA256: "00"}},
+			dir:    dir,
+		}
+		if err := m.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := OpenBundle(dir, "paraformer", nil); err == nil || !strings.Contains(err.Error(), "不在模型包目录内") {
+			t.Fatalf("path %q: expected containment error, got %v", path, err)
+		}
+	}
+}
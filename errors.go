@@ -0,0 +1,135 @@
+package speech
+
+import (
+	"context"
+	"errors"
+)
+
+// 错误类别，可通过 errors.Is 判断，错误文本为英文描述
+var (
+	// ErrEmptyAudio 输入音频为空或过短
+	ErrEmptyAudio = errors.New("empty audio")
+	// ErrInvalidAudio 输入音频无法解析
+	ErrInvalidAudio = errors.New("invalid audio")
+	// ErrUnsupportedLanguage 模型不支持该语言
+	ErrUnsupportedLanguage = errors.New("unsupported language")
+	// ErrUnsupportedTask 模型不支持该任务
+	ErrUnsupportedTask = errors.New("unsupported task")
+	// ErrUnknownSpeaker 模型中不存在该说话人
+	ErrUnknownSpeaker = errors.New("unknown speaker")
+	// ErrUnsupportedFormat 不支持的输出音频格式
+	ErrUnsupportedFormat = errors.New("unsupported output format")
+	// ErrEmptyTokenSequence 文本转换后的 Token 序列为空
+	ErrEmptyTokenSequence = errors.New("empty token sequence")
+	// ErrModelLoad 模型或运行时加载失败
+	ErrModelLoad = errors.New("model load failed")
+	// ErrInference 推理失败
+	ErrInference = errors.New("inference failed")
+)
+
+// inputErrors 由调用方输入引起的错误类别
+var inputErrors = []error{
+	ErrEmptyAudio, ErrInvalidAudio, ErrUnsupportedLanguage, ErrUnsupportedTask, ErrUnknownSpeaker, ErrUnsupportedFormat,
+	ErrEmptyTokenSequence,
+}
+
+// Error 引擎错误，携带错误类别、引擎名称与中英文描述
+//
+// errors.Is(err, Kind) 与 errors.Is(err, Err) 均成立
+type Error struct {
+	Kind    error  // 错误类别，为本包的 Err* 之一
+	Engine  string // 引擎名称，例如 "whisper"
+	Message string // 中文描述，为空时使用底层错误的描述
+	Detail  string // (可选) 相关的输入值，例如不支持的语言代码
+	Err     error  // (可选) 底层错误
+}
+
+// NewError 创建引擎错误
+//
+// # Params:
+//
+//	kind: 错误类别，为本包的 Err* 之一
+//	engine: 引擎名称
+//	message: 中文描述
+//	detail: 相关的输入值，会同时出现在中英文描述中
+func NewError(kind error, engine, message, detail string) error {
+	return &Error{Kind: kind, Engine: engine, Message: message, Detail: detail}
+}
+
+// WrapError 为底层错误标注错误类别
+//
+// err 为 nil、已带有错误类别或为 context 取消/超时时原样返回
+func WrapError(kind error, engine string, err error) error {
+	if err == nil || ErrorKind(err) != nil ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return &Error{Kind: kind, Engine: engine, Err: err}
+}
+
+// Error 返回中文描述，与之前版本的错误文本保持一致
+func (e *Error) Error() string {
+	msg := e.Message
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	switch {
+	case msg != "" && e.Err != nil:
+		return msg + ": " + e.Err.Error()
+	case msg != "":
+		return msg
+	case e.Err != nil:
+		return e.Err.Error()
+	}
+	return e.Kind.Error()
+}
+
+// English 返回英文描述，格式为 "<engine>: <kind>[: <detail>]"
+//
+// 底层错误的描述可能为中文，因此不包含在内
+func (e *Error) English() string {
+	msg := e.Kind.Error()
+	if e.Engine != "" {
+		msg = e.Engine + ": " + msg
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap 支持 errors.Is / errors.As 匹配错误类别与底层错误
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// ErrorKind 返回错误类别，未分类的错误返回 nil
+func ErrorKind(err error) error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return nil
+}
+
+// IsInputError 判断错误是否由调用方输入引起，例如空音频、不支持的语言，可映射为 HTTP 400
+func IsInputError(err error) bool {
+	for _, kind := range inputErrors {
+		if errors.Is(err, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// EnglishMessage 返回错误的英文描述，未分类的错误返回 err.Error()
+func EnglishMessage(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.English()
+	}
+	return err.Error()
+}
@@ -0,0 +1,41 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	err := fmt.Errorf("转录失败: %w", NewError(ErrUnsupportedLanguage, "whisper", "未知语言", "xx"))
+	if !errors.Is(err, ErrUnsupportedLanguage) || !IsInputError(err) {
+		t.Fatalf("unexpected classification for %v", err)
+	}
+	if err.Error() != "转录失败: 未知语言: xx" {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+	if msg := EnglishMessage(err); msg != "whisper: unsupported language: xx" {
+		t.Fatalf("unexpected english message %q", msg)
+	}
+
+	err = WrapError(ErrInference, "paraformer", io.ErrUnexpectedEOF)
+	if !errors.Is(err, ErrInference) || !errors.Is(err, io.ErrUnexpectedEOF) || IsInputError(err) {
+		t.Fatalf("unexpected classification for %v", err)
+	}
+	if err.Error() != io.ErrUnexpectedEOF.Error() {
+		t.Fatalf("unexpected message %q", err.Error())
+	}
+
+	// 已分类的错误与 context 错误保持不变
+	if again := WrapError(ErrModelLoad, "paraformer", err); ErrorKind(again) != ErrInference {
+		t.Fatal("kind must not be overwritten")
+	}
+	if WrapError(ErrInference, "whisper", context.Canceled) != context.Canceled {
+		t.Fatal("context errors must not be wrapped")
+	}
+	if WrapError(ErrInference, "whisper", nil) != nil {
+		t.Fatal("nil must stay nil")
+	}
+}
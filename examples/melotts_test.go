@@ -1,6 +1,7 @@
 package examples
 
 import (
+	"github.com/getcharzp/go-speech/tts"
 	"github.com/getcharzp/go-speech/tts/melotts"
 	"github.com/up-zero/gotool/fileutil"
 	"testing"
@@ -21,7 +22,7 @@ func TestMeloTTS(t *testing.T) {
 	defer ttsEngine.Destroy()
 
 	text := "2019年12月30日，中国人口突破14亿人。联系电话: 13800138000。"
-	wavData, err := ttsEngine.SynthesizeToWav(text, 1.0)
+	wavData, err := ttsEngine.SynthesizeToWav(text, tts.SynthesisOptions{Speed: 1.0})
 	if err != nil {
 		t.Fatalf("合成失败: %v", err)
 	}
@@ -22,10 +22,10 @@ func TestParaformer(t *testing.T) {
 	}
 	defer asrEngine.Destroy()
 
-	text, err := asrEngine.TranscribeFile("./zh-en.wav")
+	result, err := asrEngine.TranscribeFile("./zh-en.wav")
 	if err != nil {
 		t.Fatalf("识别出错: %v", err)
 		return
 	}
-	fmt.Printf("识别结果: %s\n", text)
+	fmt.Printf("识别结果: %s\n", result.Text)
 }
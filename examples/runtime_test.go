@@ -0,0 +1,52 @@
+package examples
+
+import (
+	"fmt"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr/paraformer"
+	"github.com/getcharzp/go-speech/audio"
+	"github.com/getcharzp/go-speech/tts/melotts"
+	"testing"
+)
+
+func TestSharedRuntime(t *testing.T) {
+	rt, err := speech.NewRuntime("../lib/onnxruntime.dll")
+	if err != nil {
+		t.Fatalf("初始化运行时失败: %v", err)
+	}
+	defer rt.Close()
+
+	asrCfg := paraformer.DefaultConfig()
+	asrCfg.Runtime = rt
+	asrCfg.ModelPath = "../paraformer_weights/model.int8.onnx"
+	asrCfg.TokensPath = "../paraformer_weights/tokens.txt"
+	asrCfg.CMVNPath = "../paraformer_weights/am.mvn"
+	asrEngine, err := paraformer.NewEngine(asrCfg)
+	if err != nil {
+		t.Fatalf("创建引擎失败: %v", err)
+	}
+	defer asrEngine.Destroy()
+
+	ttsCfg := melotts.DefaultConfig()
+	ttsCfg.Runtime = rt
+	ttsCfg.ModelPath = "../melo_weights/model.onnx"
+	ttsCfg.TokenPath = "../melo_weights/tokens.txt"
+	ttsCfg.LexiconPath = "../melo_weights/lexicon.txt"
+	ttsEngine, err := melotts.NewEngine(ttsCfg)
+	if err != nil {
+		t.Fatalf("创建引擎失败: %v", err)
+	}
+	defer ttsEngine.Destroy()
+
+	samples, err := ttsEngine.Synthesize("今天天气很好")
+	if err != nil {
+		t.Fatalf("合成失败: %v", err)
+	}
+	// MeloTTS 输出 44.1KHz，重采样为 16KHz 后识别
+	resampled := audio.Resample(samples, melotts.SampleRate, 16000)
+	result, err := asrEngine.Transcribe(resampled)
+	if err != nil {
+		t.Fatalf("识别出错: %v", err)
+	}
+	fmt.Printf("识别结果: %s\n", result.Text)
+}
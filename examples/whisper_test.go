@@ -20,7 +20,7 @@ func TestWhisper(t *testing.T) {
 	}
 	defer asrEngine.Destroy()
 
-	text, err := asrEngine.TranscribeFile("./zh-en.wav", whisper.TranscribeOption{
+	result, err := asrEngine.TranscribeFile("./zh-en.wav", whisper.TranscribeOption{
 		Language: whisper.LangZh,
 		Task:     whisper.TaskTranscribe,
 	})
@@ -28,5 +28,5 @@ func TestWhisper(t *testing.T) {
 		t.Fatalf("识别出错: %v", err)
 		return
 	}
-	fmt.Printf("识别结果: %s\n", text)
+	fmt.Printf("识别结果: %s\n", result.Text)
 }
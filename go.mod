@@ -3,11 +3,10 @@ module github.com/getcharzp/go-speech
 go 1.24.4
 
 require (
+	github.com/ebitengine/purego v0.9.1
 	github.com/getcharzp/onnxruntime_purego v0.0.0-20260118041137-401482b32507
+	github.com/hajimehoshi/go-mp3 v0.3.4
+	github.com/mozillazg/go-pinyin v0.21.0
 	github.com/up-zero/gotool v0.0.0-20260214093844-61edc8b0ab17
-)
-
-require (
-	github.com/ebitengine/purego v0.9.1 // indirect
-	github.com/mozillazg/go-pinyin v0.21.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
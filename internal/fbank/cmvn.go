@@ -0,0 +1,52 @@
+package fbank
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadCMVN 解析 am.mvn 文件
+// 返回 neg_mean (均值的负数) 和 inv_std (标准差的倒数)
+func LoadCMVN(path string) ([]float32, []float32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var negMean, invStd []float32
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "<LearnRateCoef>") {
+			continue
+		}
+
+		// 读取数据并转换为 float32
+		parts := strings.Fields(line)
+		values := make([]float32, 0, MelBins)
+		dataParts := parts[3 : len(parts)-1]
+		for _, v := range dataParts {
+			fVal, err := strconv.ParseFloat(v, 32)
+			if err != nil {
+				continue
+			}
+			values = append(values, float32(fVal))
+		}
+
+		if negMean == nil {
+			negMean = values
+		} else {
+			invStd = values
+			break
+		}
+	}
+
+	if len(negMean) == 0 || len(invStd) == 0 {
+		return nil, nil, fmt.Errorf("未找到有效的 CMVN 数据")
+	}
+	return negMean, invStd, nil
+}
@@ -0,0 +1,106 @@
+// Package fbank 计算 16KHz 音频的对数 Mel FilterBank 特征，供 Paraformer 与 FSMN-VAD 共用
+package fbank
+
+import (
+	"context"
+	"github.com/up-zero/gotool/mediautil"
+	"math"
+	"sync"
+)
+
+// FilterBank 参数
+const (
+	SampleRate = 16000
+	MelBins    = 80
+	FrameLen   = 400 // 25ms @ 16kHz
+	FrameShift = 160 // 10ms @ 16kHz
+	FFTSize    = 512 // Next power of 2
+	// PreEmphasis 预加重系数
+	PreEmphasis = 0.97
+)
+
+var (
+	window     []float32
+	melFilters [][]float32
+	once       sync.Once
+)
+
+// Compute 计算 FilterBank 特征，ctx 结束时在帧之间中止
+//
+// 样本不足一帧时返回 nil
+func Compute(ctx context.Context, samples []float32) ([][]float32, error) {
+	// 预加重
+	emphasized := mediautil.PreEmphasis(samples, PreEmphasis)
+
+	// 准备基础数据
+	numSamples := len(emphasized)
+	if numSamples < FrameLen {
+		return nil, nil
+	}
+
+	// 计算帧数
+	numFrames := (numSamples-FrameLen)/FrameShift + 1
+
+	// 分配结果矩阵
+	features := make([][]float32, numFrames)
+	// 预分配复数 buffer
+	fftBuffer := make([]complex128, FFTSize)
+
+	for i := 0; i < numFrames; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		start := i * FrameShift
+		features[i] = Frame(emphasized[start:start+FrameLen], fftBuffer)
+	}
+	return features, nil
+}
+
+// Frame 计算一帧预加重后样本的对数 Mel 能量
+//
+// # Params:
+//
+//	frame: FrameLen 个预加重后的样本
+//	fftBuffer: FFTSize 大小的 FFT 缓冲，可在帧之间复用
+func Frame(frame []float32, fftBuffer []complex128) []float32 {
+	once.Do(func() {
+		window = mediautil.HammingWindow(FrameLen)
+		melFilters = mediautil.MelFilters(SampleRate, FFTSize, MelBins, 0, 0)
+	})
+
+	// 加窗 & 填充 FFT buffer
+	for j := 0; j < FFTSize; j++ {
+		if j < FrameLen {
+			val := frame[j] * window[j]
+			fftBuffer[j] = complex(float64(val), 0)
+		} else {
+			fftBuffer[j] = 0 // 补零
+		}
+	}
+
+	// FFT 变换
+	spectrum := mediautil.FFT(fftBuffer)
+
+	// 计算 Mel 能量
+	features := make([]float32, MelBins)
+	for k := 0; k < MelBins; k++ {
+		sum := 0.0
+		// 遍历 FFT 结果的前半部分 (Nyquist)
+		for j := 0; j < FFTSize/2+1; j++ {
+			w := melFilters[k][j]
+			if w > 0 {
+				// Power = |X|^2
+				r := real(spectrum[j])
+				im := imag(spectrum[j])
+				power := r*r + im*im
+				sum += power * float64(w)
+			}
+		}
+
+		if sum < 1e-7 {
+			sum = 1e-7
+		}
+		features[k] = float32(math.Log(sum))
+	}
+	return features
+}
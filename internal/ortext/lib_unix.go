@@ -0,0 +1,40 @@
+//go:build linux || darwin
+
+package ortext
+
+import (
+	"fmt"
+	"github.com/ebitengine/purego"
+	"runtime"
+	"unsafe"
+)
+
+// loadLibrary 获取动态库句柄，库已被 ort.NewEngine 加载时仅增加引用计数
+func loadLibrary(name string) (uintptr, error) {
+	handle, err := purego.Dlopen(name, purego.RTLD_NOW|purego.RTLD_GLOBAL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load shared library %s: %w", name, err)
+	}
+	return handle, nil
+}
+
+// closeLibrary 释放 loadLibrary 增加的引用计数
+func closeLibrary(handle uintptr) {
+	_ = purego.Dlclose(handle)
+}
+
+// lookupSymbol 查找导出函数
+func lookupSymbol(handle uintptr, name string) (uintptr, error) {
+	return purego.Dlsym(handle, name)
+}
+
+// pathPtr 将路径转换为 UTF-8 (char*) 指针
+func pathPtr(s string) (unsafe.Pointer, []byte, error) {
+	b := make([]byte, len(s)+1)
+	copy(b, s)
+	return unsafe.Pointer(&b[0]), b, nil
+}
+
+func keepAlive(v any) {
+	runtime.KeepAlive(v)
+}
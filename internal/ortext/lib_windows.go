@@ -0,0 +1,42 @@
+//go:build windows
+
+package ortext
+
+import (
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+// loadLibrary 获取动态库句柄，库已被 ort.NewEngine 加载时仅增加引用计数
+func loadLibrary(name string) (uintptr, error) {
+	handle, err := syscall.LoadLibrary(name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load dll %s: %w", name, err)
+	}
+	return uintptr(handle), nil
+}
+
+// closeLibrary 释放 loadLibrary 增加的引用计数
+func closeLibrary(handle uintptr) {
+	_ = syscall.FreeLibrary(syscall.Handle(handle))
+}
+
+// lookupSymbol 查找导出函数
+func lookupSymbol(handle uintptr, name string) (uintptr, error) {
+	return syscall.GetProcAddress(syscall.Handle(handle), name)
+}
+
+// pathPtr 将路径转换为 UTF-16 (wchar_t*) 指针
+func pathPtr(s string) (unsafe.Pointer, []uint16, error) {
+	ptr, err := syscall.UTF16FromString(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return unsafe.Pointer(&ptr[0]), ptr, nil
+}
+
+func keepAlive(v any) {
+	runtime.KeepAlive(v)
+}
@@ -0,0 +1,201 @@
+// Package ortext 补充 onnxruntime_purego 尚未封装的 SessionOptions 设置项
+//
+// 通过 purego 直接调用 OrtApi 中对应的函数，句柄取自 ort.SessionOptions。
+// 加载时校验动态库版本与 ort.SessionOptions 的内存布局，不满足假设时返回错误而不是调用错误的函数
+package ortext
+
+import (
+	"fmt"
+	"github.com/ebitengine/purego"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// apiVersion 与 onnxruntime_purego 保持一致，函数表下标按该版本的头文件确定
+//
+// OrtApi 只在末尾追加函数，ONNX Runtime 1.N 起提供版本 N 的函数表，更早的版本不能使用
+const apiVersion = 23
+
+// OrtApi 函数表中的下标，见 onnxruntime_c_api.h
+const (
+	idxGetErrorMessage                  = 2
+	idxSetOptimizedModelFilePath        = 11
+	idxSetSessionExecutionMode          = 13
+	idxEnableMemPattern                 = 16
+	idxDisableMemPattern                = 17
+	idxSetSessionGraphOptimizationLevel = 23
+	idxSetInterOpNumThreads             = 25
+	idxReleaseStatus                    = 93
+)
+
+// GraphOptimizationLevel 对应 C 枚举 GraphOptimizationLevel
+const (
+	GraphOptDisableAll     int32 = 0
+	GraphOptEnableBasic    int32 = 1
+	GraphOptEnableExtended int32 = 2
+	GraphOptEnableAll      int32 = 99
+)
+
+// ExecutionMode 对应 C 枚举 ExecutionMode
+const (
+	ExecutionSequential int32 = 0
+	ExecutionParallel   int32 = 1
+)
+
+type apiBase struct {
+	GetAPI           uintptr
+	GetVersionString uintptr
+}
+
+type apiTable [idxReleaseStatus + 1]uintptr
+
+// API SessionOptions 扩展设置
+type API struct {
+	handle uintptr // 动态库句柄，Close 时释放
+
+	getErrorMessage           func(uintptr) string
+	releaseStatus             func(uintptr)
+	setOptimizedModelFilePath func(uintptr, unsafe.Pointer) uintptr
+	setSessionExecutionMode   func(uintptr, int32) uintptr
+	enableMemPattern          func(uintptr) uintptr
+	disableMemPattern         func(uintptr) uintptr
+	setGraphOptimizationLevel func(uintptr, int32) uintptr
+	setInterOpNumThreads      func(uintptr, int32) uintptr
+}
+
+// Load 从已加载的 ONNX Runtime 动态库中绑定函数
+//
+// # Params:
+//
+//	libPath: onnxruntime.dll (或 .so, .dylib) 的路径，需与 ort.NewEngine 使用的路径一致
+func Load(libPath string) (_ *API, err error) {
+	if err := checkLayout(); err != nil {
+		return nil, err
+	}
+	handle, err := loadLibrary(libPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			closeLibrary(handle)
+		}
+	}()
+	sym, err := lookupSymbol(handle, "OrtGetApiBase")
+	if err != nil {
+		return nil, err
+	}
+
+	var getApiBase func() *apiBase
+	purego.RegisterFunc(&getApiBase, sym)
+	base := getApiBase()
+	if base == nil {
+		return nil, fmt.Errorf("OrtGetApiBase returned nil")
+	}
+	var getVersionString func() string
+	purego.RegisterFunc(&getVersionString, base.GetVersionString)
+	if err := checkVersion(getVersionString()); err != nil {
+		return nil, err
+	}
+	var getApi func(uint32) *apiTable
+	purego.RegisterFunc(&getApi, base.GetAPI)
+	table := getApi(apiVersion)
+	if table == nil {
+		return nil, fmt.Errorf("failed to get OrtApi for version: %d", apiVersion)
+	}
+
+	a := &API{handle: handle}
+	purego.RegisterFunc(&a.getErrorMessage, table[idxGetErrorMessage])
+	purego.RegisterFunc(&a.releaseStatus, table[idxReleaseStatus])
+	purego.RegisterFunc(&a.setOptimizedModelFilePath, table[idxSetOptimizedModelFilePath])
+	purego.RegisterFunc(&a.setSessionExecutionMode, table[idxSetSessionExecutionMode])
+	purego.RegisterFunc(&a.enableMemPattern, table[idxEnableMemPattern])
+	purego.RegisterFunc(&a.disableMemPattern, table[idxDisableMemPattern])
+	purego.RegisterFunc(&a.setGraphOptimizationLevel, table[idxSetSessionGraphOptimizationLevel])
+	purego.RegisterFunc(&a.setInterOpNumThreads, table[idxSetInterOpNumThreads])
+	return a, nil
+}
+
+// Close 释放 Load 持有的动态库引用，之后不能再调用其他方法
+func (a *API) Close() {
+	if a.handle != 0 {
+		closeLibrary(a.handle)
+		a.handle = 0
+	}
+}
+
+// checkVersion 检查动态库版本不低于 1.<apiVersion>
+func checkVersion(version string) error {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) >= 2 {
+		major, err1 := strconv.Atoi(parts[0])
+		minor, err2 := strconv.Atoi(parts[1])
+		if err1 == nil && err2 == nil && (major > 1 || (major == 1 && minor >= apiVersion)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("onnxruntime %s does not provide OrtApi version %d, requires 1.%d or later", version, apiVersion, apiVersion)
+}
+
+// checkLayout 检查 ort.SessionOptions 的第一个字段是否为原生句柄，见 handleOf
+func checkLayout() error {
+	t := reflect.TypeFor[ort.SessionOptions]()
+	if t.NumField() == 0 || t.Field(0).Offset != 0 || t.Field(0).Type.Kind() != reflect.Uintptr {
+		return fmt.Errorf("unsupported onnxruntime_purego version: SessionOptions layout changed")
+	}
+	return nil
+}
+
+// SetGraphOptimizationLevel 设置图优化级别
+func (a *API) SetGraphOptimizationLevel(o *ort.SessionOptions, level int32) error {
+	return a.checkStatus(a.setGraphOptimizationLevel(handleOf(o), level))
+}
+
+// SetExecutionMode 设置执行模式 (串行/并行)
+func (a *API) SetExecutionMode(o *ort.SessionOptions, mode int32) error {
+	return a.checkStatus(a.setSessionExecutionMode(handleOf(o), mode))
+}
+
+// SetInterOpNumThreads 设置算子间并行线程数，仅在并行执行模式下生效
+func (a *API) SetInterOpNumThreads(o *ort.SessionOptions, num int32) error {
+	return a.checkStatus(a.setInterOpNumThreads(handleOf(o), num))
+}
+
+// SetMemPattern 启用或禁用内存模式优化
+func (a *API) SetMemPattern(o *ort.SessionOptions, enable bool) error {
+	if enable {
+		return a.checkStatus(a.enableMemPattern(handleOf(o)))
+	}
+	return a.checkStatus(a.disableMemPattern(handleOf(o)))
+}
+
+// SetOptimizedModelFilePath 设置优化后模型的保存路径，创建会话时写入
+func (a *API) SetOptimizedModelFilePath(o *ort.SessionOptions, path string) error {
+	ptr, keep, err := pathPtr(path)
+	if err != nil {
+		return err
+	}
+	status := a.setOptimizedModelFilePath(handleOf(o), ptr)
+	keepAlive(keep)
+	return a.checkStatus(status)
+}
+
+// checkStatus 检查状态
+func (a *API) checkStatus(status uintptr) error {
+	if status == 0 {
+		return nil
+	}
+	defer a.releaseStatus(status)
+	return fmt.Errorf("onnxruntime error: %s", a.getErrorMessage(status))
+}
+
+// handleOf 读取 ort.SessionOptions 的原生句柄
+//
+// ort.SessionOptions 的第一个字段即为 OrtSessionOptions*，在 onnxruntime_purego 提供对应方法前以此方式访问，
+// 布局由 Load 中的 checkLayout 校验
+func handleOf(o *ort.SessionOptions) uintptr {
+	return *(*uintptr)(unsafe.Pointer(o))
+}
@@ -0,0 +1,22 @@
+package ortext
+
+import "testing"
+
+func TestCheckVersion(t *testing.T) {
+	for _, v := range []string{"1.23.0", "1.24.1", "2.0.0"} {
+		if err := checkVersion(v); err != nil {
+			t.Fatalf("%s: %v", v, err)
+		}
+	}
+	for _, v := range []string{"1.22.2", "1.9.0", "", "dev"} {
+		if err := checkVersion(v); err == nil {
+			t.Fatalf("%s: expected version error", v)
+		}
+	}
+}
+
+func TestCheckLayout(t *testing.T) {
+	if err := checkLayout(); err != nil {
+		t.Fatal(err)
+	}
+}
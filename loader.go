@@ -0,0 +1,208 @@
+package speech
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/getcharzp/go-speech/asr"
+	"github.com/getcharzp/go-speech/tts"
+	"gopkg.in/yaml.v3"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// FileConfig 声明式配置文件
+//
+// # Examples:
+//
+//	onnx:
+//	  onnx_runtime_lib_path: ./lib/onnxruntime_amd64.so
+//	  num_threads: 4
+//	engines:
+//	  asr-zh:
+//	    type: paraformer
+//	    config:
+//	      model_path: ./paraformer_weights/model.int8.onnx
+//	      tokens_path: ./paraformer_weights/tokens.txt
+//	      cmvn_path: ${MODEL_DIR}/am.mvn
+type FileConfig struct {
+	Onnx      map[string]any         `json:"onnx"`       // 所有引擎共享的 ONNX 配置
+	PublicKey string                 `json:"public_key"` // (可选) 所有模型包共享的 Ed25519 签名公钥，Base64 编码
+	Engines   map[string]EngineEntry `json:"engines"`    // 实例名称 -> 引擎配置
+}
+
+// EngineEntry 配置文件中的单个引擎
+type EngineEntry struct {
+	Type      string         `json:"type"`       // 引擎类型，需已通过 Register 注册
+	Bundle    string         `json:"bundle"`     // (可选) 模型包目录，见 Manifest
+	PublicKey string         `json:"public_key"` // (可选) 模型包的 Ed25519 签名公钥，Base64 编码，覆盖共享的公钥
+	Onnx      map[string]any `json:"onnx"`       // (可选) 覆盖共享的 ONNX 配置
+	Config    map[string]any `json:"config"`     // 引擎配置，优先于模型包中的配置
+}
+
+// envVarPattern 配置值中的环境变量引用 ${VAR}
+var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// LoadConfigFile 读取 JSON 或 YAML 配置文件
+//
+// 字符串值中的 ${VAR} 会被替换为环境变量的值，其余 "$" 原样保留，根据扩展名 .yaml/.yml 判断是否为 YAML 格式
+func LoadConfigFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取配置文件: %w", err)
+	}
+
+	var node any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("解析 YAML 配置失败: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(data, &node); err != nil {
+			return nil, fmt.Errorf("解析配置失败: %w", err)
+		}
+	}
+	// 解析后再替换，环境变量中的引号、反斜杠与换行不会改变文件结构
+	if data, err = json.Marshal(expandEnvStrings(node)); err != nil {
+		return nil, err
+	}
+
+	cfg := new(FileConfig)
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置失败: %w", err)
+	}
+	return cfg, nil
+}
+
+// expandEnvStrings 替换配置树中字符串值的 ${VAR}，键保持不变
+func expandEnvStrings(node any) any {
+	switch v := node.(type) {
+	case string:
+		return envVarPattern.ReplaceAllStringFunc(v, func(ref string) string {
+			return os.Getenv(ref[2 : len(ref)-1])
+		})
+	case map[string]any:
+		for key, value := range v {
+			v[key] = expandEnvStrings(value)
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = expandEnvStrings(value)
+		}
+	}
+	return node
+}
+
+// EngineSet 由配置文件构建的一组引擎实例
+type EngineSet struct {
+	instances map[string]io.Closer
+}
+
+// LoadEngines 读取配置文件并构建所有引擎实例
+//
+// 引擎包需要先被导入以完成注册，例如:
+//
+//	import _ "github.com/getcharzp/go-speech/asr/paraformer"
+func LoadEngines(path string) (*EngineSet, error) {
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngineSet(cfg)
+}
+
+// NewEngineSet 根据配置构建所有引擎实例，任一实例创建失败时释放已创建的实例
+func NewEngineSet(cfg *FileConfig) (*EngineSet, error) {
+	set := &EngineSet{instances: make(map[string]io.Closer, len(cfg.Engines))}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Engines)) {
+		entry := cfg.Engines[name]
+		factory, ok := lookupFactory(entry.Type)
+		if !ok {
+			_ = set.Close()
+			return nil, fmt.Errorf("引擎 %s 的类型 %q 未注册, 已注册: %v", name, entry.Type, Registered())
+		}
+
+		// 合并 ONNX 配置: 实例配置优先
+		onnx := make(map[string]any, len(cfg.Onnx)+len(entry.Onnx))
+		maps.Copy(onnx, cfg.Onnx)
+		maps.Copy(onnx, entry.Onnx)
+
+		publicKey, err := ParsePublicKey(cmp.Or(entry.PublicKey, cfg.PublicKey))
+		if err != nil {
+			_ = set.Close()
+			return nil, fmt.Errorf("引擎 %s 的公钥无效: %w", name, err)
+		}
+
+		instance, err := factory(EngineSpec{
+			Name:      name,
+			Type:      entry.Type,
+			Bundle:    entry.Bundle,
+			Onnx:      onnx,
+			Config:    entry.Config,
+			PublicKey: publicKey,
+		})
+		if err != nil {
+			_ = set.Close()
+			return nil, fmt.Errorf("创建引擎 %s 失败: %w", name, err)
+		}
+		set.instances[name] = instance
+	}
+	return set, nil
+}
+
+// Names 返回所有实例名称
+func (s *EngineSet) Names() []string {
+	return slices.Sorted(maps.Keys(s.instances))
+}
+
+// Get 根据名称获取引擎实例
+func (s *EngineSet) Get(name string) (io.Closer, bool) {
+	instance, ok := s.instances[name]
+	return instance, ok
+}
+
+// Recognizer 根据名称获取语音识别引擎
+func (s *EngineSet) Recognizer(name string) (asr.Recognizer, error) {
+	instance, ok := s.instances[name]
+	if !ok {
+		return nil, fmt.Errorf("引擎 %s 不存在", name)
+	}
+	r, ok := instance.(asr.Recognizer)
+	if !ok {
+		return nil, fmt.Errorf("引擎 %s 不是语音识别引擎", name)
+	}
+	return r, nil
+}
+
+// Synthesizer 根据名称获取语音合成引擎
+func (s *EngineSet) Synthesizer(name string) (tts.Synthesizer, error) {
+	instance, ok := s.instances[name]
+	if !ok {
+		return nil, fmt.Errorf("引擎 %s 不存在", name)
+	}
+	t, ok := instance.(tts.Synthesizer)
+	if !ok {
+		return nil, fmt.Errorf("引擎 %s 不是语音合成引擎", name)
+	}
+	return t, nil
+}
+
+// Close 释放所有引擎实例
+func (s *EngineSet) Close() error {
+	var errs []error
+	for name, instance := range s.instances {
+		if err := instance.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("释放引擎 %s 失败: %w", name, err))
+		}
+		delete(s.instances, name)
+	}
+	return errors.Join(errs...)
+}
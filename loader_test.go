@@ -0,0 +1,147 @@
+package speech
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type fakeConfig struct {
+	OnnxRuntimeLibPath string  `json:"onnx_runtime_lib_path"`
+	NumThreads         int     `json:"num_threads"`
+	ModelPath          string  `json:"model_path"`
+	Speed              float32 `json:"speed"`
+}
+
+type fakeEngine struct {
+	cfg       fakeConfig
+	publicKey ed25519.PublicKey
+	closed    bool
+}
+
+func (f *fakeEngine) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestLoadEngines(t *testing.T) {
+	Register("fake", func(spec EngineSpec) (io.Closer, error) {
+		engine := &fakeEngine{publicKey: spec.PublicKey}
+		if err := spec.Decode(&engine.cfg); err != nil {
+			return nil, err
+		}
+		return engine, nil
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "speech.yaml")
+	content := `
+onnx:
+  onnx_runtime_lib_path: ./lib/onnxruntime.so
+  num_threads: 2
+engines:
+  fake-a:
+    type: fake
+    onnx:
+      num_threads: 8
+    config:
+      model_path: ${FAKE_MODEL_DIR}/model.onnx
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FAKE_MODEL_DIR", "/models")
+	t.Setenv("SPEECH_FAKE_A_SPEED", "1.5")
+
+	set, err := LoadEngines(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	instance, ok := set.Get("fake-a")
+	if !ok {
+		t.Fatal("engine fake-a not found")
+	}
+	engine := instance.(*fakeEngine)
+	want := fakeConfig{
+		OnnxRuntimeLibPath: "./lib/onnxruntime.so",
+		NumThreads:         8,
+		ModelPath:          "/models/model.onnx",
+		Speed:              1.5,
+	}
+	if engine.cfg != want {
+		t.Fatalf("got %+v, want %+v", engine.cfg, want)
+	}
+	if _, err := set.Recognizer("fake-a"); err == nil {
+		t.Fatal("expected fake engine not to be a recognizer")
+	}
+
+	if err := set.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !engine.closed {
+		t.Fatal("engine not closed")
+	}
+}
+
+func TestNewEngineSetPublicKey(t *testing.T) {
+	Register("fake-signed", func(spec EngineSpec) (io.Closer, error) {
+		return &fakeEngine{publicKey: spec.PublicKey}, nil
+	})
+	shared, _, _ := ed25519.GenerateKey(nil)
+	own, _, _ := ed25519.GenerateKey(nil)
+
+	set, err := NewEngineSet(&FileConfig{
+		PublicKey: base64.StdEncoding.EncodeToString(shared),
+		Engines: map[string]EngineEntry{
+			"a": {Type: "fake-signed"},
+			"b": {Type: "fake-signed", PublicKey: base64.StdEncoding.EncodeToString(own)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer set.Close()
+	a, _ := set.Get("a")
+	b, _ := set.Get("b")
+	if !bytes.Equal(a.(*fakeEngine).publicKey, shared) || !bytes.Equal(b.(*fakeEngine).publicKey, own) {
+		t.Fatal("public key not passed to factory")
+	}
+
+	if _, err := NewEngineSet(&FileConfig{
+		Engines: map[string]EngineEntry{"a": {Type: "fake-signed", PublicKey: "c2hvcnQ="}},
+	}); err == nil {
+		t.Fatal("expected invalid public key error")
+	}
+}
+
+func TestLoadConfigFileEnv(t *testing.T) {
+	t.Setenv("SPEECH_TEST_DIR", `C:\models "v1"`)
+	t.Setenv("SPEECH_TEST_INJECT", "x\nnum_threads: 99")
+	for name, content := range map[string]string{
+		"speech.json": `{"engines": {"a": {"type": "fake", "config": {"model_path": "${SPEECH_TEST_DIR}\\model.onnx", "price": "$5", "note": "${SPEECH_TEST_INJECT}", "label": "it's"}}}}`,
+		"speech.yaml": "engines:\n  a:\n    type: fake\n    config:\n      model_path: ${SPEECH_TEST_DIR}\\model.onnx\n      price: $5\n      note: ${SPEECH_TEST_INJECT}\n      label: it's # 注释\n",
+	} {
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := LoadConfigFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		want := map[string]any{
+			"model_path": `C:\models "v1"\model.onnx`,
+			"price":      "$5",
+			"note":       "x\nnum_threads: 99",
+			"label":      "it's",
+		}
+		if got := cfg.Engines["a"].Config; !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %#v, want %#v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package speech
+
+import "log/slog"
+
+// EngineLogger 返回带有引擎名称字段的日志记录器，logger 为空时使用 slog.Default()
+//
+// # Params:
+//
+//	logger: Config 中配置的日志记录器
+//	engine: 引擎名称，例如 "melotts"
+func EngineLogger(logger *slog.Logger, engine string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With("engine", engine)
+}
@@ -0,0 +1,127 @@
+package speech
+
+import (
+	"context"
+	"time"
+)
+
+// Observer 推理观测接口，用于接入指标与链路追踪
+//
+// 每次 Transcribe / Synthesize / VAD 检测调用结束后 (包括失败) 调用一次 Observe，实现需要并发安全且不应阻塞
+type Observer interface {
+	Observe(ctx context.Context, stats CallStats)
+}
+
+// ObserverFunc 将普通函数转换为 Observer
+type ObserverFunc func(ctx context.Context, stats CallStats)
+
+// Observe 实现 Observer 接口
+func (f ObserverFunc) Observe(ctx context.Context, stats CallStats) {
+	f(ctx, stats)
+}
+
+// 调用类型
+const (
+	OpTranscribe = "transcribe"
+	OpSynthesize = "synthesize"
+	OpDetect     = "detect"
+)
+
+// Stage 推理阶段耗时
+type Stage struct {
+	Name     string        // 阶段名称，例如 "feature", "encoder", "decoder", "g2p", "inference"
+	Duration time.Duration // 阶段耗时，同名阶段多次执行时累加
+}
+
+// CallStats 单次调用的观测数据
+type CallStats struct {
+	Engine        string        // 引擎名称
+	Op            string        // 调用类型: OpTranscribe, OpSynthesize, OpDetect
+	Start         time.Time     // 开始时间
+	Duration      time.Duration // 总耗时
+	Stages        []Stage       // 各阶段耗时，按首次执行的顺序
+	AudioDuration time.Duration // 音频时长，ASR 与 VAD 为输入音频，TTS 为输出音频
+	InputTokens   int           // 输入 Token 数，例如 TTS 的音素数、Whisper 的提示 Token 数
+	OutputTokens  int           // 输出 Token 数，例如 ASR 解码得到的 Token 数
+	Err           error         // 调用返回的错误
+}
+
+// RTF 实时率 (Real-Time Factor)，即处理耗时 / 音频时长，小于 1 表示快于实时
+func (s CallStats) RTF() float64 {
+	if s.AudioDuration <= 0 {
+		return 0
+	}
+	return s.Duration.Seconds() / s.AudioDuration.Seconds()
+}
+
+// Recorder 引擎内部使用的观测记录器
+//
+// observer 为空时 StartCall 返回 nil，所有方法对 nil 接收者均为空操作，未配置 Observer 时没有额外开销
+type Recorder struct {
+	observer Observer
+	stats    CallStats
+	mark     time.Time
+}
+
+// StartCall 开始记录一次调用
+//
+// # Params:
+//
+//	observer: Config 中配置的观测者，可为 nil
+//	engine: 引擎名称
+//	op: 调用类型
+func StartCall(observer Observer, engine, op string) *Recorder {
+	if observer == nil {
+		return nil
+	}
+	now := time.Now()
+	return &Recorder{
+		observer: observer,
+		stats:    CallStats{Engine: engine, Op: op, Start: now},
+		mark:     now,
+	}
+}
+
+// Stage 记录自上一次标记以来的耗时为 name 阶段
+func (r *Recorder) Stage(name string) {
+	if r == nil {
+		return
+	}
+	now := time.Now()
+	d := now.Sub(r.mark)
+	r.mark = now
+	for i := range r.stats.Stages {
+		if r.stats.Stages[i].Name == name {
+			r.stats.Stages[i].Duration += d
+			return
+		}
+	}
+	r.stats.Stages = append(r.stats.Stages, Stage{Name: name, Duration: d})
+}
+
+// SetAudio 根据样本数与采样率记录音频时长
+func (r *Recorder) SetAudio(samples, sampleRate int) {
+	if r == nil || sampleRate <= 0 {
+		return
+	}
+	r.stats.AudioDuration = time.Duration(float64(samples) / float64(sampleRate) * float64(time.Second))
+}
+
+// AddTokens 累加输入与输出 Token 数
+func (r *Recorder) AddTokens(input, output int) {
+	if r == nil {
+		return
+	}
+	r.stats.InputTokens += input
+	r.stats.OutputTokens += output
+}
+
+// Finish 结束记录并通知观测者
+func (r *Recorder) Finish(ctx context.Context, err error) {
+	if r == nil {
+		return
+	}
+	r.stats.Duration = time.Since(r.stats.Start)
+	r.stats.Err = err
+	r.observer.Observe(ctx, r.stats)
+}
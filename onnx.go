@@ -1,75 +1,445 @@
 package speech
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"github.com/getcharzp/go-speech/internal/ortext"
 	ort "github.com/getcharzp/onnxruntime_purego"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 )
 
 type OnnxConfig struct {
-	SessionOptions *ort.SessionOptions
-	OnnxEngine     *ort.Engine
+	SessionOptions *ort.SessionOptions `json:"-"`
+	OnnxEngine     *ort.Engine         `json:"-"`
+	// Runtime (可选) 共享的运行时，为空时 New 根据 OnnxRuntimeLibPath 创建
+	// New 会持有运行时的一次引用并在 Destroy 时释放，调用方先 Close 运行时不会影响仍在使用的引擎
+	Runtime *Runtime `json:"-"`
 
 	// 必填参数
-	OnnxRuntimeLibPath string // onnxruntime.dll (或 .so, .dylib) 的路径
+	OnnxRuntimeLibPath string `json:"onnx_runtime_lib_path"` // onnxruntime.dll (或 .so, .dylib) 的路径
 	// 可选参数
-	UseCuda    bool // (可选) 是否启用 CUDA
-	NumThreads int  // (可选) ONNX 线程数, 默认由CPU核心数决定
+	UseCuda    bool `json:"use_cuda"`    // (可选) 是否启用 CUDA
+	NumThreads int  `json:"num_threads"` // (可选) ONNX 线程数, 默认由CPU核心数决定
 
 	// EnableCpuMemArena 控制 ONNX 的内存池策略
 	// false (默认): 禁用内存池，推理速度稍慢，但 Destroy 后立即归还内存给 OS ，解决内存滞留问题
 	// true: 启用内存池，推理速度最快，但 Destroy 后内存会被缓存以供复用
-	EnableCpuMemArena bool
+	EnableCpuMemArena bool `json:"enable_cpu_mem_arena"`
+
+	// GraphOptimizationLevel (可选) 图优化级别: disable, basic, extended, all，默认 all
+	GraphOptimizationLevel string `json:"graph_optimization_level"`
+	// ExecutionMode (可选) 执行模式: sequential (默认), parallel
+	ExecutionMode string `json:"execution_mode"`
+	// InterOpNumThreads (可选) 算子间并行线程数，仅在 parallel 模式下生效
+	InterOpNumThreads int `json:"inter_op_num_threads"`
+	// DisableMemPattern (可选) 禁用内存模式优化，输入形状频繁变化时可减少内存占用
+	DisableMemPattern bool `json:"disable_mem_pattern"`
+	// OptimizedModelDir (可选) 优化后模型的缓存目录
+	// 首次创建会话时将图优化后的模型写入该目录，之后直接加载缓存并跳过图优化，以缩短冷启动时间
+	OptimizedModelDir string `json:"optimized_model_dir"`
+
+	ownRuntime bool // 运行时由 New 创建
+	holdsRef   bool // 持有运行时的一次引用
+}
+
+// New 初始化 ONNX 环境
+func (cfg *OnnxConfig) New() error {
+	if err := cfg.retainRuntime(); err != nil {
+		return err
+	}
+
+	// 创建会话选项
+	options, err := cfg.Runtime.NewSessionOptions(*cfg)
+	if err != nil {
+		cfg.Destroy()
+		return err
+	}
+	cfg.SessionOptions = options
+	cfg.OnnxEngine = cfg.Runtime.Engine()
+
+	return nil
+}
+
+// retainRuntime 创建运行时，或在共享的运行时上增加一次引用
+func (cfg *OnnxConfig) retainRuntime() error {
+	if cfg.holdsRef {
+		return nil
+	}
+	if cfg.Runtime == nil {
+		rt, err := NewRuntime(cfg.OnnxRuntimeLibPath)
+		if err != nil {
+			return err
+		}
+		cfg.Runtime = rt
+		cfg.ownRuntime = true
+	} else if err := cfg.Runtime.retain(); err != nil {
+		return err
+	}
+	cfg.holdsRef = true
+	return nil
 }
 
+// NewSession 使用当前配置创建 ONNX 会话
+//
+// 设置了 OptimizedModelDir 时优先加载已缓存的优化模型，缓存不存在时在创建会话的同时写入缓存
+//
+// # Params:
+//
+//	modelPath: 模型文件路径
+func (cfg *OnnxConfig) NewSession(modelPath string) (*ort.Session, error) {
+	if cfg.Runtime == nil || cfg.SessionOptions == nil {
+		return nil, fmt.Errorf("ONNX 环境未初始化")
+	}
+	if cfg.OptimizedModelDir == "" {
+		return cfg.OnnxEngine.NewSession(modelPath, cfg.SessionOptions)
+	}
+
+	cachePath, err := optimizedModelPath(*cfg, modelPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已有缓存: 关闭图优化后直接加载
+	if _, err := os.Stat(cachePath); err == nil {
+		cached := *cfg
+		cached.GraphOptimizationLevel = "disable"
+		options, err := cfg.Runtime.NewSessionOptions(cached)
+		if err != nil {
+			return nil, err
+		}
+		defer cfg.Runtime.ReleaseSessionOptions(options)
+		if session, err := cfg.OnnxEngine.NewSession(cachePath, options); err == nil {
+			return session, nil
+		}
+		// 缓存损坏或与当前运行时不兼容，重新生成
+		_ = os.Remove(cachePath)
+	}
+
+	if err := os.MkdirAll(cfg.OptimizedModelDir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建优化模型缓存目录失败: %w", err)
+	}
+	options, err := cfg.Runtime.NewSessionOptions(*cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer cfg.Runtime.ReleaseSessionOptions(options)
+	api, err := cfg.Runtime.ext()
+	if err != nil {
+		return nil, err
+	}
+	// 先写入临时文件，避免并发创建或中途失败时留下不完整的缓存
+	tmpPath := fmt.Sprintf("%s.%d.tmp", cachePath, os.Getpid())
+	if err := api.SetOptimizedModelFilePath(options, tmpPath); err != nil {
+		return nil, fmt.Errorf("设置优化模型缓存路径失败: %w", err)
+	}
+	session, err := cfg.OnnxEngine.NewSession(modelPath, options)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, cachePath); err != nil {
+		_ = os.Remove(tmpPath)
+	}
+	return session, nil
+}
+
+// optimizedModelPath 计算优化模型的缓存路径
+//
+// 缓存键包含模型路径、大小、修改时间与影响优化结果的配置，模型或配置变化时自动失效
+func optimizedModelPath(cfg OnnxConfig, modelPath string) (string, error) {
+	absPath, err := filepath.Abs(modelPath)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("无法读取模型文件: %w", err)
+	}
+	level, _ := graphOptimizationLevel(cfg.GraphOptimizationLevel)
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s|%d|%d|%d|%t|%s", absPath, info.Size(), info.ModTime().UnixNano(),
+		level, cfg.UseCuda, cfg.Runtime.LibPath())
+	name := strings.TrimSuffix(filepath.Base(absPath), filepath.Ext(absPath))
+	return filepath.Join(cfg.OptimizedModelDir, name+"."+hex.EncodeToString(h.Sum(nil))[:16]+".onnx"), nil
+}
+
+// graphOptimizationLevel 解析图优化级别
+func graphOptimizationLevel(level string) (int32, error) {
+	switch strings.ToLower(level) {
+	case "", "all":
+		return ortext.GraphOptEnableAll, nil
+	case "disable", "none":
+		return ortext.GraphOptDisableAll, nil
+	case "basic":
+		return ortext.GraphOptEnableBasic, nil
+	case "extended":
+		return ortext.GraphOptEnableExtended, nil
+	}
+	return 0, fmt.Errorf("不支持的图优化级别: %s (可选 disable, basic, extended, all)", level)
+}
+
+// executionMode 解析执行模式
+func executionMode(mode string) (int32, error) {
+	switch strings.ToLower(mode) {
+	case "", "sequential":
+		return ortext.ExecutionSequential, nil
+	case "parallel":
+		return ortext.ExecutionParallel, nil
+	}
+	return 0, fmt.Errorf("不支持的执行模式: %s (可选 sequential, parallel)", mode)
+}
+
+// Destroy 释放会话选项与 New 持有的运行时引用，最后一个引用释放时运行时被销毁
+//
+// 应在使用该配置创建的会话全部销毁后调用
+func (cfg *OnnxConfig) Destroy() {
+	if cfg.Runtime == nil {
+		return
+	}
+	if cfg.SessionOptions != nil {
+		cfg.Runtime.ReleaseSessionOptions(cfg.SessionOptions)
+		cfg.SessionOptions = nil
+	}
+	if cfg.holdsRef {
+		_ = cfg.Runtime.Close()
+		cfg.holdsRef = false
+	}
+	if cfg.ownRuntime {
+		cfg.Runtime = nil
+		cfg.ownRuntime = false
+	}
+	cfg.OnnxEngine = nil
+}
+
+// ErrRuntimeConflict 进程中已使用其他路径的动态库初始化 ONNX Runtime
+var ErrRuntimeConflict = errors.New("onnx runtime already initialized with a different library")
+
 var (
-	initErr    error
-	once       sync.Once
-	onnxEngine *ort.Engine
+	runtimeMu sync.Mutex
+	active    *Runtime
 )
 
-// New 初始化 ONNX 环境
-func (cfg *OnnxConfig) New() error {
-	// 初始化 ONNX Runtime
-	if cfg.OnnxRuntimeLibPath == "" {
-		return fmt.Errorf("OnnxRuntimeLibPath 不能为空")
+// Runtime ONNX Runtime 运行时，持有动态库加载后的 ort.Engine 以及由其创建的会话选项
+//
+// onnxruntime_purego 的张量创建依赖进程级的默认引擎，因此同一时间只允许一个动态库路径:
+// 使用相同路径调用 NewRuntime 会共享同一个运行时 (引用计数)，使用不同路径会返回 ErrRuntimeConflict。
+// 所有引用都 Close 后运行时被销毁，之后可以使用新的路径重新创建
+type Runtime struct {
+	libPath string
+	engine  *ort.Engine
+	refs    int // 由 runtimeMu 保护
+
+	mu      sync.Mutex
+	options map[*ort.SessionOptions]struct{}
+	api     *ortext.API // 扩展设置，首次使用时加载
+}
+
+// NewRuntime 加载 ONNX Runtime 动态库并初始化运行时
+//
+// 初始化失败不会被缓存，修正路径后可以重试。每次成功调用都需要对应一次 Close
+//
+// # Params:
+//
+//	libPath: onnxruntime.dll (或 .so, .dylib) 的路径
+func NewRuntime(libPath string) (*Runtime, error) {
+	if libPath == "" {
+		return nil, fmt.Errorf("OnnxRuntimeLibPath 不能为空")
 	}
-	once.Do(func() {
-		onnxEngine, initErr = ort.NewEngine(cfg.OnnxRuntimeLibPath)
-	})
-	if initErr != nil {
-		return fmt.Errorf("初始化 ONNX Runtime 环境失败: %w", initErr)
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+
+	if active != nil {
+		if !samePath(active.libPath, libPath) {
+			return nil, fmt.Errorf("%w: 已加载 %s, 请求 %s", ErrRuntimeConflict, active.libPath, libPath)
+		}
+		active.refs++
+		return active, nil
 	}
 
-	// 创建会话选项 (设置线程)
-	options, err := onnxEngine.NewSessionOptions()
+	engine, err := ort.NewEngine(libPath)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("初始化 ONNX Runtime 环境失败: %w", err)
+	}
+	active = &Runtime{
+		libPath: libPath,
+		engine:  engine,
+		refs:    1,
+		options: make(map[*ort.SessionOptions]struct{}),
+	}
+	return active, nil
+}
+
+// retain 增加一次引用，运行时已销毁时返回错误
+func (r *Runtime) retain() error {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	if r.refs <= 0 {
+		return fmt.Errorf("ONNX Runtime 已关闭")
+	}
+	r.refs++
+	return nil
+}
+
+// LibPath 返回动态库路径
+func (r *Runtime) LibPath() string {
+	return r.libPath
+}
+
+// Engine 返回底层的 ort.Engine
+func (r *Runtime) Engine() *ort.Engine {
+	return r.engine
+}
+
+// ext 加载 onnxruntime_purego 未封装的扩展设置，加载失败不会被缓存
+func (r *Runtime) ext() (*ortext.API, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.api == nil {
+		api, err := ortext.Load(r.libPath)
+		if err != nil {
+			return nil, fmt.Errorf("加载 ONNX Runtime 扩展接口失败: %w", err)
+		}
+		r.api = api
+	}
+	return r.api, nil
+}
+
+// NewSessionOptions 根据配置创建会话选项，运行时销毁时会释放尚未释放的会话选项
+func (r *Runtime) NewSessionOptions(cfg OnnxConfig) (*ort.SessionOptions, error) {
+	level, err := graphOptimizationLevel(cfg.GraphOptimizationLevel)
+	if err != nil {
+		return nil, err
+	}
+	mode, err := executionMode(cfg.ExecutionMode)
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := r.engine.NewSessionOptions()
+	if err != nil {
+		return nil, err
 	}
+
+	// 设置线程
 	if cfg.NumThreads > 0 {
 		if err := options.SetIntraOpNumThreads(int32(cfg.NumThreads)); err != nil {
-			return err
+			options.Destroy()
+			return nil, err
 		}
 	}
 
 	// 设置内存策略
 	if err := options.SetCpuMemArena(cfg.EnableCpuMemArena); err != nil {
-		return fmt.Errorf("设置 CPU 内存池失败: %w", err)
+		options.Destroy()
+		return nil, fmt.Errorf("设置 CPU 内存池失败: %w", err)
+	}
+
+	// 图优化、执行模式与内存模式，均为默认值时不加载扩展接口
+	if level != ortext.GraphOptEnableAll || mode != ortext.ExecutionSequential ||
+		cfg.InterOpNumThreads > 0 || cfg.DisableMemPattern {
+		if err := r.applyExt(options, cfg, level, mode); err != nil {
+			options.Destroy()
+			return nil, err
+		}
 	}
 
 	// 启用CUDA
 	if cfg.UseCuda {
 		if err := options.EnableCUDA(); err != nil {
-			return fmt.Errorf("启用 CUDA 失败: %w", err)
+			options.Destroy()
+			return nil, fmt.Errorf("启用 CUDA 失败: %w", err)
 		}
 	}
-	cfg.SessionOptions = options
-	cfg.OnnxEngine = onnxEngine
 
+	r.mu.Lock()
+	r.options[options] = struct{}{}
+	r.mu.Unlock()
+	return options, nil
+}
+
+// applyExt 设置 onnxruntime_purego 未封装的会话选项
+func (r *Runtime) applyExt(options *ort.SessionOptions, cfg OnnxConfig, level, mode int32) error {
+	api, err := r.ext()
+	if err != nil {
+		return err
+	}
+	if err := api.SetGraphOptimizationLevel(options, level); err != nil {
+		return fmt.Errorf("设置图优化级别失败: %w", err)
+	}
+	if err := api.SetExecutionMode(options, mode); err != nil {
+		return fmt.Errorf("设置执行模式失败: %w", err)
+	}
+	if cfg.InterOpNumThreads > 0 {
+		if err := api.SetInterOpNumThreads(options, int32(cfg.InterOpNumThreads)); err != nil {
+			return fmt.Errorf("设置算子间线程数失败: %w", err)
+		}
+	}
+	if cfg.DisableMemPattern {
+		if err := api.SetMemPattern(options, false); err != nil {
+			return fmt.Errorf("禁用内存模式失败: %w", err)
+		}
+	}
 	return nil
 }
 
+// ReleaseSessionOptions 释放由 NewSessionOptions 创建的会话选项
+func (r *Runtime) ReleaseSessionOptions(options *ort.SessionOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.options[options]; ok {
+		delete(r.options, options)
+		options.Destroy()
+	}
+}
+
+// Close 释放一次引用，最后一次引用释放时销毁会话选项与 ort.Engine
+//
+// 使用该运行时创建的引擎各自持有一次引用，因此可以在引擎之前 Close
+func (r *Runtime) Close() error {
+	runtimeMu.Lock()
+	defer runtimeMu.Unlock()
+	if r.refs <= 0 {
+		return fmt.Errorf("ONNX Runtime 已关闭")
+	}
+	r.refs--
+	if r.refs > 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	for options := range r.options {
+		options.Destroy()
+	}
+	clear(r.options)
+	if r.api != nil {
+		r.api.Close()
+		r.api = nil
+	}
+	r.mu.Unlock()
+
+	r.engine.Destroy()
+	if active == r {
+		active = nil
+	}
+	return nil
+}
+
+// samePath 判断两个动态库路径是否指向同一文件
+func samePath(a, b string) bool {
+	if a == b {
+		return true
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
 // DefaultLibraryPath 根据运行时环境判断加载哪个库文件
 func DefaultLibraryPath() string {
 	baseDir := "./lib/"
@@ -0,0 +1,64 @@
+package speech
+
+import (
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"testing"
+)
+
+func TestNewRuntimeRetry(t *testing.T) {
+	if _, err := NewRuntime(""); err == nil {
+		t.Fatal("expected empty path error")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := NewRuntime("./not_exist/onnxruntime.so"); err == nil {
+			t.Fatal("expected load error")
+		}
+		if active != nil {
+			t.Fatal("failed init must not be cached")
+		}
+	}
+}
+
+func TestSessionTuningOptions(t *testing.T) {
+	for _, level := range []string{"", "disable", "basic", "extended", "ALL"} {
+		if _, err := graphOptimizationLevel(level); err != nil {
+			t.Fatalf("level %q: %v", level, err)
+		}
+	}
+	if _, err := graphOptimizationLevel("max"); err == nil {
+		t.Fatal("expected invalid level error")
+	}
+	if mode, err := executionMode("parallel"); err != nil || mode != 1 {
+		t.Fatalf("mode = %d, %v", mode, err)
+	}
+	if _, err := executionMode("async"); err == nil {
+		t.Fatal("expected invalid mode error")
+	}
+}
+
+func TestRuntimeCloseBeforeEngine(t *testing.T) {
+	rt := &Runtime{engine: new(ort.Engine), refs: 1, options: make(map[*ort.SessionOptions]struct{})}
+	cfg := OnnxConfig{Runtime: rt}
+	if err := cfg.retainRuntime(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 调用方先关闭运行时，引擎仍持有引用
+	if err := rt.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if rt.refs != 1 {
+		t.Fatalf("refs = %d, want 1", rt.refs)
+	}
+
+	cfg.Destroy()
+	if rt.refs != 0 {
+		t.Fatalf("refs = %d, want 0", rt.refs)
+	}
+	if cfg.Runtime != rt {
+		t.Fatal("shared runtime must stay on config")
+	}
+	if err := (&OnnxConfig{Runtime: rt}).retainRuntime(); err == nil {
+		t.Fatal("expected closed runtime error")
+	}
+}
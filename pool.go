@@ -0,0 +1,245 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrPoolClosed 池已关闭
+	ErrPoolClosed = errors.New("pool closed")
+	// ErrPoolQueueFull 等待队列已满
+	ErrPoolQueueFull = errors.New("pool queue full")
+)
+
+// PoolItem 可放入 Pool 的引擎实例，通常为 *Engine
+type PoolItem interface {
+	comparable
+	io.Closer
+}
+
+// PoolConfig 引擎池配置
+type PoolConfig struct {
+	Size int // 实例数量，默认 1
+	// MaxQueue 最大等待数量
+	// 0 (默认): 不限制; 小于 0: 不等待，没有空闲实例时直接返回 ErrPoolQueueFull
+	MaxQueue int
+}
+
+// PoolStats 引擎池运行状态
+type PoolStats struct {
+	Size        int           // 实例数量
+	InUse       int           // 正在使用的实例数量
+	Waiting     int           // 正在等待的调用方数量
+	Utilization float64       // 当前利用率 InUse / Size
+	Acquired    uint64        // 累计获取次数
+	Rejected    uint64        // 因队列已满被拒绝的次数
+	WaitTime    time.Duration // 累计等待时长
+	BusyTime    time.Duration // 累计占用时长，BusyTime / (Size * 运行时长) 即平均利用率
+}
+
+// Pool 并发安全的引擎池
+//
+// 引擎实例 (以及其持有的 *ort.Session) 都不是并发安全的，Pool 持有 N 个实例并在同一时间只将每个实例交给一个调用方。
+// 适用于 paraformer、whisper、melotts、pipertts 等所有引擎
+//
+// # Examples:
+//
+//	pool, err := speech.NewPool(speech.PoolConfig{Size: 4, MaxQueue: 16}, func() (*paraformer.Engine, error) {
+//		return paraformer.NewEngine(cfg)
+//	})
+//	err = pool.Do(ctx, func(e *paraformer.Engine) error {
+//		result, err = e.Transcribe(samples)
+//		return err
+//	})
+type Pool[T PoolItem] struct {
+	cfg   PoolConfig
+	idle  chan T
+	items []T
+
+	mu       sync.Mutex
+	closed   bool
+	inUse    int
+	waiting  int
+	acquired uint64
+	rejected uint64
+	waitTime time.Duration
+	busyTime time.Duration
+	since    map[T]time.Time // 实例 -> 获取时间
+	done     chan struct{}
+}
+
+// NewPool 创建引擎池，任一实例创建失败时释放已创建的实例
+//
+// # Params:
+//
+//	cfg: 池配置
+//	factory: 实例构造函数，会被调用 cfg.Size 次
+func NewPool[T PoolItem](cfg PoolConfig, factory func() (T, error)) (*Pool[T], error) {
+	if cfg.Size <= 0 {
+		cfg.Size = 1
+	}
+	p := &Pool[T]{
+		cfg:   cfg,
+		idle:  make(chan T, cfg.Size),
+		items: make([]T, 0, cfg.Size),
+		since: make(map[T]time.Time, cfg.Size),
+		done:  make(chan struct{}),
+	}
+	for i := 0; i < cfg.Size; i++ {
+		item, err := factory()
+		if err != nil {
+			_ = p.Close()
+			return nil, fmt.Errorf("创建第 %d 个实例失败: %w", i+1, err)
+		}
+		p.items = append(p.items, item)
+		p.idle <- item
+	}
+	return p, nil
+}
+
+// Acquire 获取一个空闲实例，没有空闲实例时等待，使用完毕后必须调用 Release 归还
+//
+// 等待队列已满返回 ErrPoolQueueFull，池关闭返回 ErrPoolClosed，ctx 结束返回 ctx.Err()
+func (p *Pool[T]) Acquire(ctx context.Context) (T, error) {
+	var zero T
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return zero, ErrPoolClosed
+	}
+
+	// 快速路径: 有空闲实例
+	select {
+	case item := <-p.idle:
+		p.checkout(item, 0)
+		p.mu.Unlock()
+		return item, nil
+	default:
+	}
+
+	if p.cfg.MaxQueue < 0 || (p.cfg.MaxQueue > 0 && p.waiting >= p.cfg.MaxQueue) {
+		p.rejected++
+		p.mu.Unlock()
+		return zero, ErrPoolQueueFull
+	}
+	p.waiting++
+	p.mu.Unlock()
+
+	start := time.Now()
+	select {
+	case item := <-p.idle:
+		p.mu.Lock()
+		p.waiting--
+		// 与 Close 同时就绪时 select 随机选择，拿到的实例在 Close 中已不再可见，由调用方释放
+		if p.closed {
+			p.mu.Unlock()
+			_ = item.Close()
+			return zero, ErrPoolClosed
+		}
+		p.checkout(item, time.Since(start))
+		p.mu.Unlock()
+		return item, nil
+	case <-p.done:
+		p.mu.Lock()
+		p.waiting--
+		p.mu.Unlock()
+		return zero, ErrPoolClosed
+	case <-ctx.Done():
+		p.mu.Lock()
+		p.waiting--
+		p.waitTime += time.Since(start)
+		p.mu.Unlock()
+		return zero, ctx.Err()
+	}
+}
+
+// checkout 记录实例被取出，调用方需持有 p.mu
+func (p *Pool[T]) checkout(item T, wait time.Duration) {
+	p.inUse++
+	p.acquired++
+	p.waitTime += wait
+	p.since[item] = time.Now()
+}
+
+// Release 归还由 Acquire 获取的实例
+func (p *Pool[T]) Release(item T) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	start, ok := p.since[item]
+	if !ok {
+		return // 重复归还或不属于该池
+	}
+	p.busyTime += time.Since(start)
+	delete(p.since, item)
+	p.inUse--
+	// 池关闭后归还的实例在此释放，避免释放正在推理的会话
+	if p.closed {
+		_ = item.Close()
+		return
+	}
+	p.idle <- item
+}
+
+// Do 获取一个实例执行 fn，执行结束后自动归还
+func (p *Pool[T]) Do(ctx context.Context, fn func(T) error) error {
+	item, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.Release(item)
+	return fn(item)
+}
+
+// Stats 返回池的运行状态
+func (p *Pool[T]) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := PoolStats{
+		Size:     len(p.items),
+		InUse:    p.inUse,
+		Waiting:  p.waiting,
+		Acquired: p.acquired,
+		Rejected: p.rejected,
+		WaitTime: p.waitTime,
+		BusyTime: p.busyTime,
+	}
+	// 累计占用时长包含仍在使用中的实例
+	now := time.Now()
+	for _, start := range p.since {
+		stats.BusyTime += now.Sub(start)
+	}
+	if stats.Size > 0 {
+		stats.Utilization = float64(stats.InUse) / float64(stats.Size)
+	}
+	return stats
+}
+
+// Close 关闭池并释放空闲实例
+//
+// 等待中的调用方返回 ErrPoolClosed，正在使用的实例在 Release 归还时释放
+func (p *Pool[T]) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	close(p.done)
+
+	var errs []error
+	for {
+		select {
+		case item := <-p.idle:
+			if err := item.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			return errors.Join(errs...)
+		}
+	}
+}
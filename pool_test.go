@@ -0,0 +1,156 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type pooledEngine struct {
+	closed atomic.Bool
+}
+
+func (e *pooledEngine) Close() error {
+	e.closed.Store(true)
+	return nil
+}
+
+func TestPool(t *testing.T) {
+	var created []*pooledEngine
+	pool, err := NewPool(PoolConfig{Size: 2, MaxQueue: 1}, func() (*pooledEngine, error) {
+		e := new(pooledEngine)
+		created = append(created, e)
+		return e, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	a, _ := pool.Acquire(ctx)
+	b, _ := pool.Acquire(ctx)
+	if a == b {
+		t.Fatal("same instance handed out twice")
+	}
+	if s := pool.Stats(); s.InUse != 2 || s.Utilization != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	// 一个调用方排队，第二个被拒绝
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := pool.Do(ctx, func(e *pooledEngine) error { return nil }); err != nil {
+			t.Error(err)
+		}
+	}()
+	for pool.Stats().Waiting != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := pool.Acquire(ctx); !errors.Is(err, ErrPoolQueueFull) {
+		t.Fatalf("expected ErrPoolQueueFull, got %v", err)
+	}
+	pool.Release(a)
+	wg.Wait()
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	c, _ := pool.Acquire(ctx)
+	if _, err := pool.Acquire(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+	pool.Release(b)
+	pool.Release(c)
+
+	s := pool.Stats()
+	if s.InUse != 0 || s.Acquired != 4 || s.Rejected != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range created {
+		if !e.closed.Load() {
+			t.Fatal("instance not closed")
+		}
+	}
+	if _, err := pool.Acquire(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPoolCloseInUse(t *testing.T) {
+	pool, err := NewPool(PoolConfig{Size: 1}, func() (*pooledEngine, error) { return new(pooledEngine), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	a, _ := pool.Acquire(ctx)
+
+	waitErr := make(chan error, 1)
+	go func() {
+		_, err := pool.Acquire(ctx)
+		waitErr <- err
+	}()
+	for pool.Stats().Waiting != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-waitErr; !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+	// 正在使用的实例在归还时才释放
+	if a.closed.Load() {
+		t.Fatal("in-use instance closed")
+	}
+	pool.Release(a)
+	if !a.closed.Load() {
+		t.Fatal("released instance not closed")
+	}
+}
+
+func TestPoolCloseRace(t *testing.T) {
+	for range 200 {
+		pool, err := NewPool(PoolConfig{Size: 1}, func() (*pooledEngine, error) { return new(pooledEngine), nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, _ := pool.Acquire(context.Background())
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			item, err := pool.Acquire(context.Background())
+			if err != nil {
+				return
+			}
+			// 拿到的实例不能已被释放
+			if item.closed.Load() {
+				t.Error("acquired a closed instance")
+			}
+			pool.Release(item)
+		}()
+		for pool.Stats().Waiting != 1 {
+			time.Sleep(time.Microsecond)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.Release(a)
+		}()
+		_ = pool.Close()
+		wg.Wait()
+		if !a.closed.Load() {
+			t.Fatal("instance leaked")
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package speech
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 默认直方图桶
+var (
+	// DefaultDurationBuckets 耗时直方图桶 (秒)
+	DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	// DefaultRTFBuckets 实时率直方图桶
+	DefaultRTFBuckets = []float64{0.01, 0.02, 0.05, 0.1, 0.2, 0.5, 1, 2, 5}
+)
+
+// PrometheusObserver 以 Prometheus 文本格式导出观测数据的 Observer，无外部依赖
+//
+// 导出的指标 (namespace 默认为 speech):
+//
+//	speech_requests_total{engine, op, status}                 调用次数，status 为 ok, error, canceled
+//	speech_request_duration_seconds{engine, op}               调用耗时直方图
+//	speech_stage_duration_seconds{engine, op, stage}          阶段耗时直方图
+//	speech_real_time_factor{engine, op}                       实时率直方图
+//	speech_audio_seconds_total{engine, op}                    处理的音频总时长
+//	speech_tokens_total{engine, op, direction}                Token 总数，direction 为 input, output
+//
+// # Examples:
+//
+//	metrics := speech.NewPrometheusObserver("")
+//	cfg.Observer = metrics
+//	http.Handle("/metrics", metrics)
+type PrometheusObserver struct {
+	mu       sync.Mutex
+	families []*promFamily
+
+	requests      *promFamily
+	duration      *promFamily
+	stageDuration *promFamily
+	rtf           *promFamily
+	audio         *promFamily
+	tokens        *promFamily
+}
+
+// promFamily 同名指标
+type promFamily struct {
+	name    string
+	help    string
+	typ     string    // counter, histogram
+	buckets []float64 // 仅 histogram
+	series  map[string]*promSeries
+}
+
+// promSeries 一组标签对应的指标值
+type promSeries struct {
+	value  float64  // counter
+	counts []uint64 // histogram 各桶计数 (非累计)
+	sum    float64
+	count  uint64
+}
+
+// NewPrometheusObserver 创建 Prometheus 观测者
+//
+// # Params:
+//
+//	namespace: 指标名称前缀，为空时使用 "speech"
+func NewPrometheusObserver(namespace string) *PrometheusObserver {
+	if namespace == "" {
+		namespace = "speech"
+	}
+	p := new(PrometheusObserver)
+	family := func(name, help, typ string, buckets []float64) *promFamily {
+		f := &promFamily{name: namespace + "_" + name, help: help, typ: typ, buckets: buckets, series: make(map[string]*promSeries)}
+		p.families = append(p.families, f)
+		return f
+	}
+	p.requests = family("requests_total", "Total number of inference calls.", "counter", nil)
+	p.duration = family("request_duration_seconds", "Inference call duration in seconds.", "histogram", DefaultDurationBuckets)
+	p.stageDuration = family("stage_duration_seconds", "Inference stage duration in seconds.", "histogram", DefaultDurationBuckets)
+	p.rtf = family("real_time_factor", "Processing time divided by audio duration.", "histogram", DefaultRTFBuckets)
+	p.audio = family("audio_seconds_total", "Total duration of processed audio in seconds.", "counter", nil)
+	p.tokens = family("tokens_total", "Total number of input and output tokens.", "counter", nil)
+	return p
+}
+
+// Observe 实现 Observer 接口
+func (p *PrometheusObserver) Observe(_ context.Context, stats CallStats) {
+	status := "ok"
+	switch {
+	case errors.Is(stats.Err, context.Canceled), errors.Is(stats.Err, context.DeadlineExceeded):
+		status = "canceled"
+	case stats.Err != nil:
+		status = "error"
+	}
+	base := promLabels("engine", stats.Engine, "op", stats.Op)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests.add(base+","+promLabels("status", status), 1)
+	p.duration.observe(base, stats.Duration.Seconds())
+	for _, stage := range stats.Stages {
+		p.stageDuration.observe(base+","+promLabels("stage", stage.Name), stage.Duration.Seconds())
+	}
+	if stats.Err != nil {
+		return
+	}
+	if stats.AudioDuration > 0 {
+		p.rtf.observe(base, stats.RTF())
+		p.audio.add(base, stats.AudioDuration.Seconds())
+	}
+	if stats.InputTokens > 0 {
+		p.tokens.add(base+","+promLabels("direction", "input"), float64(stats.InputTokens))
+	}
+	if stats.OutputTokens > 0 {
+		p.tokens.add(base+","+promLabels("direction", "output"), float64(stats.OutputTokens))
+	}
+}
+
+// WriteTo 以 Prometheus 文本格式 (0.0.4) 写出所有指标
+func (p *PrometheusObserver) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	p.mu.Lock()
+	for _, f := range p.families {
+		f.write(bw)
+	}
+	p.mu.Unlock()
+
+	if err := bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// ServeHTTP 实现 http.Handler，可直接挂载到 /metrics
+func (p *PrometheusObserver) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+// get 获取或创建指标
+func (f *promFamily) get(labels string) *promSeries {
+	s, ok := f.series[labels]
+	if !ok {
+		s = &promSeries{counts: make([]uint64, len(f.buckets))}
+		f.series[labels] = s
+	}
+	return s
+}
+
+// add 累加 counter
+func (f *promFamily) add(labels string, v float64) {
+	f.get(labels).value += v
+}
+
+// observe 记录 histogram 样本
+func (f *promFamily) observe(labels string, v float64) {
+	s := f.get(labels)
+	if i, _ := slices.BinarySearch(f.buckets, v); i < len(f.buckets) {
+		s.counts[i]++
+	}
+	s.sum += v
+	s.count++
+}
+
+// write 写出同名指标
+func (f *promFamily) write(w *bufio.Writer) {
+	if len(f.series) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.typ)
+	for _, labels := range slices.Sorted(maps.Keys(f.series)) {
+		s := f.series[labels]
+		if f.typ == "counter" {
+			fmt.Fprintf(w, "%s{%s} %s\n", f.name, labels, promFloat(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, le := range f.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", f.name, labels, promFloat(le), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", f.name, labels, s.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", f.name, labels, promFloat(s.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", f.name, labels, s.count)
+	}
+}
+
+// promLabels 格式化标签对，值按 Prometheus 规则转义
+func promLabels(kv ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(kv); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(kv[i])
+		sb.WriteString(`="`)
+		for _, r := range kv[i+1] {
+			switch r {
+			case '\\':
+				sb.WriteString(`\\`)
+			case '"':
+				sb.WriteString(`\"`)
+			case '\n':
+				sb.WriteString(`\n`)
+			default:
+				sb.WriteRune(r)
+			}
+		}
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+// promFloat 格式化浮点数
+func promFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countWriter 统计写出的字节数
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,69 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusObserver(t *testing.T) {
+	p := NewPrometheusObserver("")
+	p.Observe(context.Background(), CallStats{
+		Engine:        "whisper",
+		Op:            OpTranscribe,
+		Duration:      500 * time.Millisecond,
+		Stages:        []Stage{{Name: "encoder", Duration: 200 * time.Millisecond}},
+		AudioDuration: 5 * time.Second,
+		InputTokens:   4,
+		OutputTokens:  20,
+	})
+	p.Observe(context.Background(), CallStats{Engine: "whisper", Op: OpTranscribe, Err: errors.New("boom")})
+	p.Observe(context.Background(), CallStats{Engine: "whisper", Op: OpTranscribe, Err: context.Canceled})
+
+	var sb strings.Builder
+	if _, err := p.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	out := sb.String()
+	for _, want := range []string{
+		"# TYPE speech_requests_total counter",
+		`speech_requests_total{engine="whisper",op="transcribe",status="ok"} 1`,
+		`speech_requests_total{engine="whisper",op="transcribe",status="error"} 1`,
+		`speech_requests_total{engine="whisper",op="transcribe",status="canceled"} 1`,
+		`speech_stage_duration_seconds_bucket{engine="whisper",op="transcribe",stage="encoder",le="0.25"} 1`,
+		`speech_real_time_factor_bucket{engine="whisper",op="transcribe",le="0.1"} 1`,
+		`speech_real_time_factor_count{engine="whisper",op="transcribe"} 1`,
+		`speech_audio_seconds_total{engine="whisper",op="transcribe"} 5`,
+		`speech_tokens_total{engine="whisper",op="transcribe",direction="output"} 20`,
+		`speech_request_duration_seconds_count{engine="whisper",op="transcribe"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	var got CallStats
+	rec := StartCall(ObserverFunc(func(_ context.Context, s CallStats) { got = s }), "melotts", OpSynthesize)
+	rec.Stage("g2p")
+	rec.Stage("inference")
+	rec.Stage("g2p")
+	rec.AddTokens(10, 0)
+	rec.SetAudio(44100, 44100)
+	rec.Finish(context.Background(), nil)
+
+	if len(got.Stages) != 2 || got.Stages[0].Name != "g2p" || got.InputTokens != 10 || got.AudioDuration != time.Second {
+		t.Fatalf("unexpected stats %+v", got)
+	}
+	if got.RTF() <= 0 {
+		t.Fatal("expected positive RTF")
+	}
+
+	// 未配置观测者时为空操作
+	var nilRec *Recorder = StartCall(nil, "melotts", OpSynthesize)
+	nilRec.Stage("g2p")
+	nilRec.Finish(context.Background(), nil)
+}
@@ -0,0 +1,155 @@
+package speech
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Factory 根据配置创建引擎实例
+//
+// 返回值通常实现 asr.Recognizer 或 tts.Synthesizer 接口
+type Factory func(spec EngineSpec) (io.Closer, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register 注册引擎构造函数，通常在引擎包的 init 中调用
+//
+// # Params:
+//
+//	kind: 引擎类型，例如："paraformer", "whisper", "melotts", "piper"
+//	factory: 构造函数
+func Register(kind string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("speech: Register factory is nil")
+	}
+	if _, dup := factories[kind]; dup {
+		panic("speech: Register called twice for engine " + kind)
+	}
+	factories[kind] = factory
+}
+
+// Registered 返回已注册的引擎类型
+func Registered() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	return slices.Sorted(maps.Keys(factories))
+}
+
+// lookupFactory 查找引擎构造函数
+func lookupFactory(kind string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	f, ok := factories[kind]
+	return f, ok
+}
+
+// EngineSpec 单个引擎实例的配置
+type EngineSpec struct {
+	Name   string         // 实例名称
+	Type   string         // 引擎类型
+	Bundle string         // 模型包目录，为空表示不使用模型包
+	Onnx   map[string]any // ONNX 运行时配置，例如 "num_threads"
+	Config map[string]any // 引擎配置，例如 "model_path"
+
+	// PublicKey 模型包清单的 Ed25519 公钥，非空时引擎应在加载模型包时校验签名
+	PublicKey ed25519.PublicKey
+}
+
+// Decode 将配置解析到引擎的 Config 结构体中
+//
+// ONNX 配置与引擎配置合并后按 json tag 解析，随后使用环境变量覆盖，
+// 环境变量格式为 SPEECH_<实例名称>_<json tag>，例如: SPEECH_ASR_ZH_MODEL_PATH
+func (s EngineSpec) Decode(v any) error {
+	merged := make(map[string]any, len(s.Onnx)+len(s.Config))
+	maps.Copy(merged, s.Onnx)
+	maps.Copy(merged, s.Config)
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("解析引擎 %s 配置失败: %w", s.Name, err)
+	}
+	return applyEnvOverrides(v, envPrefix(s.Name))
+}
+
+// envPrefix 生成实例的环境变量前缀
+func envPrefix(name string) string {
+	var sb strings.Builder
+	sb.WriteString("SPEECH_")
+	for _, r := range strings.ToUpper(name) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	sb.WriteByte('_')
+	return sb.String()
+}
+
+// applyEnvOverrides 使用环境变量覆盖结构体中带 json tag 的字段
+func applyEnvOverrides(v any, prefix string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		tag, _, _ := strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		key := prefix + strings.ToUpper(tag)
+		val, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		field := rv.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(val)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return fmt.Errorf("环境变量 %s 不是合法的布尔值: %w", key, err)
+			}
+			field.SetBool(b)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return fmt.Errorf("环境变量 %s 不是合法的整数: %w", key, err)
+			}
+			field.SetInt(n)
+		case reflect.Float32, reflect.Float64:
+			f, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return fmt.Errorf("环境变量 %s 不是合法的浮点数: %w", key, err)
+			}
+			field.SetFloat(f)
+		default:
+			if err := json.Unmarshal([]byte(val), field.Addr().Interface()); err != nil {
+				return fmt.Errorf("环境变量 %s 解析失败: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package speech
+
+import (
+	"fmt"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"slices"
+	"strings"
+)
+
+// CheckSession 检查 ONNX 会话的输入输出名称与引擎期望一致
+//
+// 在首次推理前发现模型与配置不匹配 (例如 Whisper 的 ModelLayers 错误)，而不是在 Run 时失败
+//
+// # Params:
+//
+//	session: ONNX 会话
+//	inputs: 引擎会提供的全部输入
+//	outputs: 引擎需要读取的输出
+func CheckSession(session *ort.Session, inputs, outputs []string) error {
+	var missingIn, unfedIn, missingOut []string
+	for _, name := range inputs {
+		if !slices.Contains(session.InputNames, name) {
+			missingIn = append(missingIn, name)
+		}
+	}
+	for _, name := range session.InputNames {
+		if !slices.Contains(inputs, name) {
+			unfedIn = append(unfedIn, name)
+		}
+	}
+	for _, name := range outputs {
+		if !slices.Contains(session.OutputNames, name) {
+			missingOut = append(missingOut, name)
+		}
+	}
+	if len(missingIn) == 0 && len(unfedIn) == 0 && len(missingOut) == 0 {
+		return nil
+	}
+
+	var details []string
+	if len(missingIn) > 0 {
+		details = append(details, "模型缺少输入 ["+strings.Join(missingIn, ", ")+"]")
+	}
+	if len(unfedIn) > 0 {
+		details = append(details, "引擎未提供输入 ["+strings.Join(unfedIn, ", ")+"]")
+	}
+	if len(missingOut) > 0 {
+		details = append(details, "模型缺少输出 ["+strings.Join(missingOut, ", ")+"]")
+	}
+	return fmt.Errorf("模型输入输出不匹配: %s", strings.Join(details, "; "))
+}
@@ -1,30 +1,44 @@
 package melotts
 
-import "github.com/getcharzp/go-speech"
+import (
+	"github.com/getcharzp/go-speech"
+	"io"
+	"log/slog"
+)
 
 const (
+	// engineName 引擎名称，用于注册、日志与错误
+	engineName = "melotts"
 	// SampleRate 采样率，默认为 44100
 	SampleRate = 44100
 	// speakerID 说话人 ID
 	speakerID = 1
-	// channels 声道数
-	channels = 1
-	// bitsPerSample 采样位数
-	bitsPerSample = 16
+	// speakerName 说话人名称
+	speakerName = "ZH"
 )
 
 // Config 定义 MeloTTS 引擎的配置参数
 type Config struct {
 	// 必填参数
-	OnnxRuntimeLibPath string // onnxruntime.dll (或 .so, .dylib) 的路径
-	ModelPath          string // ONNX 模型路径
-	TokenPath          string // tokens.txt 路径
-	LexiconPath        string // lexicon.txt 路径
+	OnnxRuntimeLibPath string `json:"onnx_runtime_lib_path"` // onnxruntime.dll (或 .so, .dylib) 的路径
+	ModelPath          string `json:"model_path"`            // ONNX 模型路径
+	TokenPath          string `json:"token_path"`            // tokens.txt 路径
+	LexiconPath        string `json:"lexicon_path"`          // lexicon.txt 路径
 
 	// 可选参数
-	UseCuda           bool // (可选) 是否启用 CUDA
-	NumThreads        int  // (可选) ONNX 线程数, 默认由CPU核心数决定
-	EnableCpuMemArena bool // (可选) 是否启用内存池
+	UseCuda                bool            `json:"use_cuda"`                 // (可选) 是否启用 CUDA
+	NumThreads             int             `json:"num_threads"`              // (可选) ONNX 线程数, 默认由CPU核心数决定
+	EnableCpuMemArena      bool            `json:"enable_cpu_mem_arena"`     // (可选) 是否启用内存池
+	GraphOptimizationLevel string          `json:"graph_optimization_level"` // (可选) 图优化级别: disable, basic, extended, all
+	ExecutionMode          string          `json:"execution_mode"`           // (可选) 执行模式: sequential, parallel
+	InterOpNumThreads      int             `json:"inter_op_num_threads"`     // (可选) 算子间并行线程数，仅 parallel 模式生效
+	DisableMemPattern      bool            `json:"disable_mem_pattern"`      // (可选) 禁用内存模式优化
+	OptimizedModelDir      string          `json:"optimized_model_dir"`      // (可选) 优化后模型的缓存目录，用于缩短冷启动时间
+	TargetSampleRate       int             `json:"target_sample_rate"`       // (可选) 输出采样率，与模型采样率不同时进行带限重采样，默认使用模型采样率
+	ResampleQuality        string          `json:"resample_quality"`         // (可选) 重采样质量: low, medium, high，默认 medium
+	Runtime                *speech.Runtime `json:"-"`                        // (可选) 共享的 ONNX 运行时，设置后忽略 OnnxRuntimeLibPath
+	Logger                 *slog.Logger    `json:"-"`                        // (可选) 日志记录器，默认使用 slog.Default()
+	Observer               speech.Observer `json:"-"`                        // (可选) 推理观测者，接收各阶段耗时、音频时长、Token 数与实时率
 }
 
 // DefaultConfig 返回一套默认的配置 (基于常见的目录结构)
@@ -37,6 +51,26 @@ func DefaultConfig() Config {
 	}
 }
 
+func init() {
+	speech.Register(engineName, func(spec speech.EngineSpec) (io.Closer, error) {
+		cfg := DefaultConfig()
+		if spec.Bundle != "" {
+			var err error
+			if cfg, err = ConfigFromBundle(spec.Bundle, cfg, spec.PublicKey); err != nil {
+				return nil, err
+			}
+		}
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		engine, err := NewEngine(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	})
+}
+
 // LexiconItem 存储音素和对应的声调信息
 type LexiconItem struct {
 	// Phones 音素
@@ -1,24 +1,50 @@
 package melotts
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/audio"
+	"github.com/getcharzp/go-speech/tts"
 	ort "github.com/getcharzp/onnxruntime_purego"
 	"github.com/up-zero/gotool/convertutil"
-	"github.com/up-zero/gotool/mediautil"
+	"log/slog"
 )
 
 // Engine 封装了 MeloTTS 的 ONNX 运行时和相关资源
 // 不仅持有模型会话，还缓存了分词器和词典数据
+//
+// Engine 不是并发安全的，并发推理请使用 speech.Pool 持有多个实例
 type Engine struct {
+	onnx     *speech.OnnxConfig
 	session  *ort.Session
 	lexicon  map[string]LexiconItem
 	tokenMap map[string]int64
 	config   Config
+	logger   *slog.Logger
+	observer speech.Observer
+
+	outputRate int           // 输出采样率
+	quality    audio.Quality // 重采样质量
 }
 
+var _ tts.Synthesizer = (*Engine)(nil)
+
 // NewEngine 初始化 MeloTTS 引擎
-func NewEngine(cfg Config) (*Engine, error) {
+//
+// 失败时返回的错误满足 errors.Is(err, speech.ErrModelLoad)
+func NewEngine(cfg Config) (_ *Engine, err error) {
+	defer func() { err = speech.WrapError(speech.ErrModelLoad, engineName, err) }()
+
+	quality, err := audio.ParseQuality(cfg.ResampleQuality)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TargetSampleRate < 0 {
+		return nil, fmt.Errorf("输出采样率不能为负数: %d", cfg.TargetSampleRate)
+	}
+
 	oc := new(speech.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 
@@ -28,30 +54,47 @@ func NewEngine(cfg Config) (*Engine, error) {
 	}
 
 	if cfg.ModelPath == "" || cfg.TokenPath == "" || cfg.LexiconPath == "" {
+		oc.Destroy()
 		return nil, fmt.Errorf("模型、Tokens 和 Lexicon 文件路径不能为空")
 	}
 
 	// 加载资源 (Tokens 和 Lexicon)
 	tokenMap, err := loadTokens(cfg.TokenPath)
 	if err != nil {
+		oc.Destroy()
 		return nil, fmt.Errorf("加载 Tokens 失败: %w", err)
 	}
-	lexicon, err := loadLexicon(cfg.LexiconPath)
+	logger := speech.EngineLogger(cfg.Logger, engineName)
+	lexicon, err := loadLexicon(cfg.LexiconPath, logger)
 	if err != nil {
+		oc.Destroy()
 		return nil, fmt.Errorf("加载 Lexicon 失败: %w", err)
 	}
 
 	// 创建 ONNX 会话
-	session, err := oc.OnnxEngine.NewSession(cfg.ModelPath, oc.SessionOptions)
+	session, err := oc.NewSession(cfg.ModelPath)
 	if err != nil {
+		oc.Destroy()
 		return nil, fmt.Errorf("创建 ONNX 会话失败: %w", err)
 	}
+	inputNames := []string{"x", "x_lengths", "tones", "sid", "noise_scale", "length_scale", "noise_scale_w"}
+	if err := speech.CheckSession(session, inputNames, []string{"y"}); err != nil {
+		session.Destroy()
+		oc.Destroy()
+		return nil, err
+	}
 
 	return &Engine{
+		onnx:     oc,
 		session:  session,
 		lexicon:  lexicon,
 		tokenMap: tokenMap,
 		config:   cfg,
+		logger:   logger,
+		observer: cfg.Observer,
+
+		outputRate: cmp.Or(cfg.TargetSampleRate, SampleRate),
+		quality:    quality,
 	}, nil
 }
 
@@ -60,34 +103,117 @@ func NewEngine(cfg Config) (*Engine, error) {
 // # Params:
 //
 //	text: 需要转换的文本
-//	speed: 语速调节,数值越大越快,1.0为正常语速
-func (e *Engine) Synthesize(text string, speed float32) ([]float32, error) {
+//	opt: 合成可选参数，例如语速 Speed (数值越大越快, 1.0为正常语速)
+func (e *Engine) Synthesize(text string, opt ...tts.SynthesisOptions) ([]float32, error) {
+	return e.SynthesizeContext(context.Background(), text, opt...)
+}
+
+// SynthesizeContext 将文本转换为语音数据 (float32 PCM)，文本按句切分后逐句合成，ctx 结束时在句子之间中止
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	text: 需要转换的文本
+//	opt: 合成可选参数，例如语速 Speed (数值越大越快, 1.0为正常语速)
+func (e *Engine) SynthesizeContext(ctx context.Context, text string, opt ...tts.SynthesisOptions) (_ []float32, err error) {
+	rec := speech.StartCall(e.observer, engineName, speech.OpSynthesize)
+	defer func() { rec.Finish(ctx, err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	var o tts.SynthesisOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	speaker, ok := e.Info().FindSpeaker(o.Speaker)
+	if !ok {
+		return nil, speech.NewError(speech.ErrUnknownSpeaker, engineName, "未知说话人", o.Speaker)
+	}
+
 	// 文本标准化
 	normalizedText := convertutil.TextToChinese(text)
 
-	// 文本转 ID (G2P)
-	inputIDs, toneIDs, err := e.textToIds(normalizedText)
-	if err != nil {
-		return nil, fmt.Errorf("G2P 转换失败: %w", err)
+	var pcm []float32
+	var lastErr error
+	for _, sentence := range tts.SplitSentences(normalizedText) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// 文本转 ID (G2P)，仅含标点等无法发音的句子跳过
+		inputIDs, toneIDs, err := e.textToIds(sentence, o.Diagnostics)
+		rec.Stage("g2p")
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		rec.AddTokens(len(inputIDs), 0)
+
+		// 执行 ONNX 推理
+		data, err := e.runInference(inputIDs, toneIDs, speaker.ID, o)
+		if err != nil {
+			return nil, err
+		}
+		rec.Stage("inference")
+		pcm = append(pcm, data...)
+	}
+	if len(pcm) == 0 {
+		if lastErr == nil {
+			lastErr = speech.NewError(speech.ErrEmptyTokenSequence, engineName, "生成的 Token 序列为空", "")
+		}
+		return nil, fmt.Errorf("G2P 转换失败: %w", lastErr)
 	}
 
-	// 执行 ONNX 推理
-	return e.runInference(inputIDs, toneIDs, speed)
+	// 转换为输出采样率
+	if e.outputRate != SampleRate {
+		pcm = audio.ResampleQuality(pcm, SampleRate, e.outputRate, e.quality)
+		rec.Stage("resample")
+	}
+	rec.SetAudio(len(pcm), e.outputRate)
+	return pcm, nil
+}
+
+// SynthesizeToWav 将文本转换为 16 位 PCM WAV 格式的字节流
+//
+// # Params:
+//
+//	text: 需要转换的文本
+//	opt: 合成可选参数，例如语速 Speed (数值越大越快, 1.0为正常语速)
+func (e *Engine) SynthesizeToWav(text string, opt ...tts.SynthesisOptions) ([]byte, error) {
+	return e.SynthesizeTo(text, audio.OutputWAV, opt...)
 }
 
-// SynthesizeToWav 将文本转换为 WAV 格式的字节流
+// SynthesizeTo 将文本转换为指定格式的字节流
 //
 // # Params:
 //
 //	text: 需要转换的文本
-//	speed: 语速调节,数值越大越快,1.0为正常语速
-func (e *Engine) SynthesizeToWav(text string, speed float32) ([]byte, error) {
-	pcmData, err := e.Synthesize(text, speed)
+//	format: 输出格式，包括样本编码、采样率、声道数与封装格式，采样率为 0 时使用 Info().SampleRate
+//	opt: 合成可选参数，例如语速 Speed (数值越大越快, 1.0为正常语速)
+//
+// # Examples:
+//
+//	ulaw, err := engine.SynthesizeTo(text, audio.OutputMuLaw8k)
+//	wav, err := engine.SynthesizeTo(text, audio.OutputFormat{Encoding: audio.EncodingALaw, SampleRate: 8000, Container: audio.ContainerWAV})
+func (e *Engine) SynthesizeTo(text string, format audio.OutputFormat, opt ...tts.SynthesisOptions) ([]byte, error) {
+	if err := format.Validate(); err != nil {
+		return nil, &speech.Error{Kind: speech.ErrUnsupportedFormat, Engine: engineName, Message: "不支持的输出格式", Err: err}
+	}
+	pcm, err := e.Synthesize(text, opt...)
 	if err != nil {
 		return nil, err
 	}
+	if format.Quality == 0 {
+		format.Quality = e.quality
+	}
+	return audio.Encode(&audio.Buffer{SampleRate: e.outputRate, Channels: 1, Data: pcm}, format)
+}
 
-	return mediautil.Float32ToWavBytes(pcmData, SampleRate, channels, bitsPerSample)
+// Info 返回引擎的音频与音色信息
+func (e *Engine) Info() tts.Info {
+	return tts.Info{
+		SampleRate: e.outputRate,
+		Languages:  []string{"zh", "en"},
+		Speakers:   []tts.Speaker{{ID: speakerID, Name: speakerName}},
+	}
 }
 
 // Destroy 释放相关资源
@@ -95,10 +221,19 @@ func (e *Engine) Destroy() {
 	if e.session != nil {
 		e.session.Destroy()
 	}
+	if e.onnx != nil {
+		e.onnx.Destroy()
+	}
+}
+
+// Close 释放相关资源，实现 tts.Synthesizer 接口
+func (e *Engine) Close() error {
+	e.Destroy()
+	return nil
 }
 
 // runInference 推理
-func (e *Engine) runInference(inputIDs []int64, toneIDs []int64, speed float32) ([]float32, error) {
+func (e *Engine) runInference(inputIDs []int64, toneIDs []int64, sid int64, opt tts.SynthesisOptions) ([]float32, error) {
 	seqLength := int64(len(inputIDs))
 
 	// 构建张量
@@ -117,7 +252,7 @@ func (e *Engine) runInference(inputIDs []int64, toneIDs []int64, speed float32)
 		return nil, fmt.Errorf("创建 tones tensor 失败: %w", err)
 	}
 	defer tTones.Destroy()
-	tSid, err := ort.NewTensor([]int64{1}, []int64{speakerID})
+	tSid, err := ort.NewTensor([]int64{1}, []int64{sid})
 	if err != nil {
 		return nil, fmt.Errorf("创建 sid tensor 失败: %w", err)
 	}
@@ -127,11 +262,17 @@ func (e *Engine) runInference(inputIDs []int64, toneIDs []int64, speed float32)
 	// noise_scale (0.667), length_scale (1.0 / speed), noise_scale_w (0.8)
 	// length_scale 控制语速，值越大语速越慢，所以用 1.0/speed
 	noiseScale := float32(0.667)
+	if opt.NoiseScale > 0 {
+		noiseScale = opt.NoiseScale
+	}
 	lengthScale := float32(1.0)
-	if speed > 0 {
-		lengthScale = 1.0 / speed
+	if opt.Speed > 0 {
+		lengthScale = 1.0 / opt.Speed
 	}
 	noiseScaleW := float32(0.8)
+	if opt.NoiseScaleW > 0 {
+		noiseScaleW = opt.NoiseScaleW
+	}
 
 	tNoise, err := ort.NewTensor([]int64{1}, []float32{noiseScale})
 	if err != nil {
@@ -1,12 +1,15 @@
 package melotts
 
 import (
-	"fmt"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/tts"
 	"strings"
 )
 
 // textToIds 将标准化后的文本转换为 Token ID 和 Tone ID 序列
-func (e *Engine) textToIds(text string) ([]int64, []int64, error) {
+//
+// 无法转换的字符会被丢弃，记录到日志并写入 diag (可为 nil)
+func (e *Engine) textToIds(text string, diag *tts.Diagnostics) ([]int64, []int64, error) {
 	var ids []int64
 	var tones []int64
 
@@ -20,7 +23,7 @@ func (e *Engine) textToIds(text string) ([]int64, []int64, error) {
 		lowerWord := strings.ToLower(word)
 
 		// 查词典 (优先全匹配)
-		if e.appendIdsFromLexicon(lowerWord, &ids, &tones) {
+		if e.appendIdsFromLexicon(lowerWord, &ids, &tones, diag) {
 			continue
 		}
 
@@ -40,7 +43,7 @@ func (e *Engine) textToIds(text string) ([]int64, []int64, error) {
 				lowerChar := strings.ToLower(charStr)
 
 				// 尝试单字符查词典 (如 'a' -> [phone...])
-				if e.appendIdsFromLexicon(lowerChar, &subIds, &subTones) {
+				if e.appendIdsFromLexicon(lowerChar, &subIds, &subTones, diag) {
 					continue
 				}
 
@@ -51,7 +54,8 @@ func (e *Engine) textToIds(text string) ([]int64, []int64, error) {
 				}
 
 				// 单字符也无法处理
-				fmt.Printf("[WARN] OOV 字符丢失: %s (in %s)\n", charStr, word)
+				e.logger.Warn("OOV 字符丢失", "token", charStr, "word", word, "text", text)
+				diag.AddDropped(charStr, text, "oov_char")
 			}
 
 			if len(subIds) > 0 {
@@ -61,21 +65,24 @@ func (e *Engine) textToIds(text string) ([]int64, []int64, error) {
 			}
 		}
 
-		// 完全无法处理
-		fmt.Printf("[WARN] 跳过完全未识别字符/单词: %s\n", word)
+		// 完全无法处理，多字符单词已逐字符记录
+		e.logger.Warn("跳过完全未识别字符/单词", "token", word, "text", text)
+		if len(runes) <= 1 {
+			diag.AddDropped(word, text, "oov_char")
+		}
 	}
 
 	// 结尾 Pad
 	e.appendToken(0, 0, &ids, &tones)
 
 	if len(ids) <= 1 {
-		return nil, nil, fmt.Errorf("生成的 Token 序列为空")
+		return nil, nil, speech.NewError(speech.ErrEmptyTokenSequence, engineName, "生成的 Token 序列为空", "")
 	}
 	return ids, tones, nil
 }
 
 // appendIdsFromLexicon 尝试从词典查找并追加 IDs，返回是否成功
-func (e *Engine) appendIdsFromLexicon(key string, ids *[]int64, tones *[]int64) bool {
+func (e *Engine) appendIdsFromLexicon(key string, ids *[]int64, tones *[]int64, diag *tts.Diagnostics) bool {
 	item, ok := e.lexicon[key]
 	if !ok {
 		return false
@@ -84,7 +91,8 @@ func (e *Engine) appendIdsFromLexicon(key string, ids *[]int64, tones *[]int64)
 	for i, phone := range item.Phones {
 		id, ok := e.tokenMap[phone]
 		if !ok {
-			fmt.Printf("[ERROR] Lexicon 含未知音素: %s (key: %s)\n", phone, key)
+			e.logger.Error("Lexicon 含未知音素", "phone", phone, "key", key)
+			diag.AddDropped(phone, key, "unknown_phoneme")
 			continue
 		}
 		tVal := item.Tones[i]
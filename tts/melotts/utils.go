@@ -3,6 +3,7 @@ package melotts
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
@@ -11,7 +12,7 @@ import (
 // loadLexicon 加载发音词典
 //
 // 数据格式: word phone1 phone2 ... tone1 tone2 ...
-func loadLexicon(path string) (map[string]LexiconItem, error) {
+func loadLexicon(path string, logger *slog.Logger) (map[string]LexiconItem, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -21,7 +22,9 @@ func loadLexicon(path string) (map[string]LexiconItem, error) {
 	lex := make(map[string]LexiconItem)
 	scanner := bufio.NewScanner(file)
 
+	lineNo := 0
 	for scanner.Scan() {
+		lineNo++
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
@@ -36,7 +39,7 @@ func loadLexicon(path string) (map[string]LexiconItem, error) {
 
 		// 音素数量必须等于声调数量，所以剩余部分必须是偶数
 		if len(rest)%2 != 0 {
-			fmt.Printf("Skipping invalid lexicon line: %s\n", word)
+			logger.Warn("跳过无效的词典行", "path", path, "line", lineNo, "word", word)
 			continue
 		}
 
@@ -1,8 +1,14 @@
 package pipertts
 
+import (
+	"github.com/getcharzp/go-speech"
+	"io"
+	"log/slog"
+)
+
 const (
-	channels      = 1
-	bitsPerSample = 16
+	// engineName 引擎名称，用于注册、日志与错误
+	engineName = "piper"
 )
 
 // PiperConfig 对应 .onnx.json 配置文件
@@ -14,6 +20,10 @@ type PiperConfig struct {
 	Espeak struct {
 		Voice string `json:"voice"`
 	} `json:"espeak"`
+	Language struct {
+		Code   string `json:"code"`
+		Family string `json:"family"`
+	} `json:"language"`
 	PhonemeType string `json:"phoneme_type"`
 	NumSymbols  int    `json:"num_symbols"`
 	NumSpeakers int    `json:"num_speakers"`
@@ -23,10 +33,57 @@ type PiperConfig struct {
 		NoiseW      float32 `json:"noise_w"`
 	} `json:"inference"`
 	PhonemeIDMap map[string][]int64 `json:"phoneme_id_map"`
+	SpeakerIDMap map[string]int64   `json:"speaker_id_map"`
 }
 
+// Config 定义 Piper 引擎的配置参数
 type Config struct {
-	OnnxRuntimeLibPath string
-	ModelPath          string
-	ConfigPath         string
+	// 必填参数
+	OnnxRuntimeLibPath string `json:"onnx_runtime_lib_path"` // onnxruntime.dll (或 .so, .dylib) 的路径
+	ModelPath          string `json:"model_path"`            // ONNX 模型路径
+	ConfigPath         string `json:"config_path"`           // .onnx.json 配置文件路径
+
+	// 可选参数
+	UseCuda                bool            `json:"use_cuda"`                 // (可选) 是否启用 CUDA
+	NumThreads             int             `json:"num_threads"`              // (可选) ONNX 线程数, 默认由CPU核心数决定
+	EnableCpuMemArena      bool            `json:"enable_cpu_mem_arena"`     // (可选) 是否启用内存池
+	GraphOptimizationLevel string          `json:"graph_optimization_level"` // (可选) 图优化级别: disable, basic, extended, all
+	ExecutionMode          string          `json:"execution_mode"`           // (可选) 执行模式: sequential, parallel
+	InterOpNumThreads      int             `json:"inter_op_num_threads"`     // (可选) 算子间并行线程数，仅 parallel 模式生效
+	DisableMemPattern      bool            `json:"disable_mem_pattern"`      // (可选) 禁用内存模式优化
+	OptimizedModelDir      string          `json:"optimized_model_dir"`      // (可选) 优化后模型的缓存目录，用于缩短冷启动时间
+	TargetSampleRate       int             `json:"target_sample_rate"`       // (可选) 输出采样率，与模型采样率不同时进行带限重采样，默认使用模型采样率
+	ResampleQuality        string          `json:"resample_quality"`         // (可选) 重采样质量: low, medium, high，默认 medium
+	Runtime                *speech.Runtime `json:"-"`                        // (可选) 共享的 ONNX 运行时，设置后忽略 OnnxRuntimeLibPath
+	Logger                 *slog.Logger    `json:"-"`                        // (可选) 日志记录器，默认使用 slog.Default()
+	Observer               speech.Observer `json:"-"`                        // (可选) 推理观测者，接收各阶段耗时、音频时长、Token 数与实时率
+}
+
+// DefaultConfig 返回一套默认的配置 (基于常见的目录结构)
+func DefaultConfig() Config {
+	return Config{
+		OnnxRuntimeLibPath: speech.DefaultLibraryPath(),
+		ModelPath:          "./pipertts_weights/zh_CN-xiao_ya-medium.onnx",
+		ConfigPath:         "./pipertts_weights/zh_CN-xiao_ya-medium.onnx.json",
+	}
+}
+
+func init() {
+	speech.Register(engineName, func(spec speech.EngineSpec) (io.Closer, error) {
+		cfg := DefaultConfig()
+		if spec.Bundle != "" {
+			var err error
+			if cfg, err = ConfigFromBundle(spec.Bundle, cfg, spec.PublicKey); err != nil {
+				return nil, err
+			}
+		}
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		engine, err := NewEngine(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	})
 }
@@ -1,22 +1,48 @@
 package pipertts
 
 import (
+	"cmp"
+	"context"
 	"fmt"
 	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/audio"
+	"github.com/getcharzp/go-speech/tts"
 	ort "github.com/getcharzp/onnxruntime_purego"
 	"github.com/up-zero/gotool/convertutil"
-	"github.com/up-zero/gotool/mediautil"
+	"log/slog"
+	"slices"
 )
 
 // Engine Piper-TTS 引擎结构
+//
+// Engine 不是并发安全的，并发推理请使用 speech.Pool 持有多个实例
 type Engine struct {
+	onnx        *speech.OnnxConfig
 	session     *ort.Session
 	piperConfig PiperConfig
 	config      Config
+	logger      *slog.Logger
+	observer    speech.Observer
+	outputRate  int           // 输出采样率
+	quality     audio.Quality // 重采样质量
 }
 
+var _ tts.Synthesizer = (*Engine)(nil)
+
 // NewEngine 初始化 Piper 引擎
-func NewEngine(cfg Config) (*Engine, error) {
+//
+// 失败时返回的错误满足 errors.Is(err, speech.ErrModelLoad)
+func NewEngine(cfg Config) (_ *Engine, err error) {
+	defer func() { err = speech.WrapError(speech.ErrModelLoad, engineName, err) }()
+
+	quality, err := audio.ParseQuality(cfg.ResampleQuality)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.TargetSampleRate < 0 {
+		return nil, fmt.Errorf("输出采样率不能为负数: %d", cfg.TargetSampleRate)
+	}
+
 	oc := new(speech.OnnxConfig)
 	_ = convertutil.CopyProperties(cfg, oc)
 
@@ -27,47 +53,162 @@ func NewEngine(cfg Config) (*Engine, error) {
 	// 读取 Piper 专属配置 (.onnx.json)
 	piperCfg, err := loadPiperConfig(cfg.ConfigPath)
 	if err != nil {
+		oc.Destroy()
 		return nil, fmt.Errorf("加载 Piper 配置失败: %w", err)
 	}
 
 	// 创建 ONNX 会话
-	session, err := oc.OnnxEngine.NewSession(cfg.ModelPath, oc.SessionOptions)
+	session, err := oc.NewSession(cfg.ModelPath)
 	if err != nil {
+		oc.Destroy()
 		return nil, fmt.Errorf("创建会话失败: %w", err)
 	}
+	inputNames := []string{"input", "input_lengths", "scales"}
+	if piperCfg.NumSpeakers > 1 {
+		inputNames = append(inputNames, "sid")
+	}
+	if err := speech.CheckSession(session, inputNames, []string{"output"}); err != nil {
+		session.Destroy()
+		oc.Destroy()
+		return nil, err
+	}
 
 	return &Engine{
+		onnx:        oc,
 		session:     session,
 		piperConfig: piperCfg,
 		config:      cfg,
+		logger:      speech.EngineLogger(cfg.Logger, engineName),
+		observer:    cfg.Observer,
+		outputRate:  cmp.Or(cfg.TargetSampleRate, piperCfg.Audio.SampleRate),
+		quality:     quality,
 	}, nil
 }
 
 // Synthesize 合成 PCM 数据
-func (e *Engine) Synthesize(text string) ([]float32, error) {
+//
+// # Params:
+//
+//	text: 需要转换的文本
+//	opt: 合成可选参数，零值使用 .onnx.json 中的推理参数
+func (e *Engine) Synthesize(text string, opt ...tts.SynthesisOptions) ([]float32, error) {
+	return e.SynthesizeContext(context.Background(), text, opt...)
+}
+
+// SynthesizeContext 合成 PCM 数据，文本按句切分后逐句合成，ctx 结束时在句子之间中止
+//
+// # Params:
+//
+//	ctx: 上下文，用于取消与超时
+//	text: 需要转换的文本
+//	opt: 合成可选参数，零值使用 .onnx.json 中的推理参数
+func (e *Engine) SynthesizeContext(ctx context.Context, text string, opt ...tts.SynthesisOptions) (_ []float32, err error) {
+	rec := speech.StartCall(e.observer, engineName, speech.OpSynthesize)
+	defer func() { rec.Finish(ctx, err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	var o tts.SynthesisOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	speaker, ok := e.Info().FindSpeaker(o.Speaker)
+	if !ok {
+		return nil, speech.NewError(speech.ErrUnknownSpeaker, engineName, "未知说话人", o.Speaker)
+	}
+
 	// 文本标准化
 	text = convertutil.TextToChinese(text)
 
-	inputIDs := e.textToIds(text)
-	if len(inputIDs) == 0 {
-		return nil, fmt.Errorf("音素序列转换结果为空")
+	var pcm []float32
+	for _, sentence := range tts.SplitSentences(text) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		inputIDs := e.textToIds(sentence, o.Diagnostics)
+		rec.Stage("g2p")
+		if len(inputIDs) == 0 {
+			continue
+		}
+		rec.AddTokens(len(inputIDs), 0)
+
+		data, err := e.runInference(inputIDs, speaker.ID, o)
+		if err != nil {
+			return nil, err
+		}
+		rec.Stage("inference")
+		pcm = append(pcm, data...)
+	}
+	if len(pcm) == 0 {
+		return nil, speech.NewError(speech.ErrEmptyTokenSequence, engineName, "音素序列转换结果为空", "")
 	}
 
-	return e.runInference(inputIDs)
+	// 转换为输出采样率
+	if modelRate := e.piperConfig.Audio.SampleRate; e.outputRate != modelRate {
+		pcm = audio.ResampleQuality(pcm, modelRate, e.outputRate, e.quality)
+		rec.Stage("resample")
+	}
+	rec.SetAudio(len(pcm), e.outputRate)
+	return pcm, nil
+}
+
+// SynthesizeToWav 将文本转换为 16 位 PCM WAV 格式的字节流
+//
+// # Params:
+//
+//	text: 需要转换的文本
+//	opt: 合成可选参数，零值使用 .onnx.json 中的推理参数
+func (e *Engine) SynthesizeToWav(text string, opt ...tts.SynthesisOptions) ([]byte, error) {
+	return e.SynthesizeTo(text, audio.OutputWAV, opt...)
 }
 
-// SynthesizeToWav 合成并导出为 WAV 字节流
-func (e *Engine) SynthesizeToWav(phonemesText string) ([]byte, error) {
-	pcmData, err := e.Synthesize(phonemesText)
+// SynthesizeTo 将文本转换为指定格式的字节流
+//
+// # Params:
+//
+//	text: 需要转换的文本
+//	format: 输出格式，包括样本编码、采样率、声道数与封装格式，采样率为 0 时使用 Info().SampleRate
+//	opt: 合成可选参数，零值使用 .onnx.json 中的推理参数
+//
+// # Examples:
+//
+//	ulaw, err := engine.SynthesizeTo(text, audio.OutputMuLaw8k)
+//	wav, err := engine.SynthesizeTo(text, audio.OutputFormat{Encoding: audio.EncodingALaw, SampleRate: 8000, Container: audio.ContainerWAV})
+func (e *Engine) SynthesizeTo(text string, format audio.OutputFormat, opt ...tts.SynthesisOptions) ([]byte, error) {
+	if err := format.Validate(); err != nil {
+		return nil, &speech.Error{Kind: speech.ErrUnsupportedFormat, Engine: engineName, Message: "不支持的输出格式", Err: err}
+	}
+	pcm, err := e.Synthesize(text, opt...)
 	if err != nil {
 		return nil, err
 	}
+	if format.Quality == 0 {
+		format.Quality = e.quality
+	}
+	return audio.Encode(&audio.Buffer{SampleRate: e.outputRate, Channels: 1, Data: pcm}, format)
+}
 
-	return mediautil.Float32ToWavBytes(pcmData, e.piperConfig.Audio.SampleRate, channels, bitsPerSample)
+// Info 返回引擎的音频与音色信息
+func (e *Engine) Info() tts.Info {
+	info := tts.Info{SampleRate: e.outputRate}
+
+	// 语言: 优先使用语系，其次使用语言代码与 espeak 音色
+	lang := cmp.Or(e.piperConfig.Language.Family, e.piperConfig.Language.Code, e.piperConfig.Espeak.Voice)
+	if lang != "" {
+		info.Languages = []string{lang}
+	}
+
+	// 说话人: 按 ID 排序
+	for name, id := range e.piperConfig.SpeakerIDMap {
+		info.Speakers = append(info.Speakers, tts.Speaker{ID: id, Name: name})
+	}
+	slices.SortFunc(info.Speakers, func(a, b tts.Speaker) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return info
 }
 
 // runInference 执行 ONNX 推理
-func (e *Engine) runInference(inputIDs []int64) ([]float32, error) {
+func (e *Engine) runInference(inputIDs []int64, sid int64, opt tts.SynthesisOptions) ([]float32, error) {
 	seqLength := int64(len(inputIDs))
 
 	// input [1, phonemes]
@@ -85,10 +226,15 @@ func (e *Engine) runInference(inputIDs []int64) ([]float32, error) {
 	defer tInputLengths.Destroy()
 
 	// scales [3] [noise_scale, length_scale, noise_w]
+	// length_scale 控制语速，值越大语速越慢，所以除以 speed
+	lengthScale := e.piperConfig.Inference.LengthScale
+	if opt.Speed > 0 {
+		lengthScale /= opt.Speed
+	}
 	scalesData := []float32{
-		e.piperConfig.Inference.NoiseScale,
-		e.piperConfig.Inference.LengthScale,
-		e.piperConfig.Inference.NoiseW,
+		cmp.Or(opt.NoiseScale, e.piperConfig.Inference.NoiseScale),
+		lengthScale,
+		cmp.Or(opt.NoiseScaleW, e.piperConfig.Inference.NoiseW),
 	}
 	tScales, err := ort.NewTensor([]int64{3}, scalesData)
 	if err != nil {
@@ -102,6 +248,16 @@ func (e *Engine) runInference(inputIDs []int64) ([]float32, error) {
 		"scales":        tScales,
 	}
 
+	// 多说话人模型需要 sid [1]
+	if e.piperConfig.NumSpeakers > 1 {
+		tSid, err := ort.NewTensor([]int64{1}, []int64{sid})
+		if err != nil {
+			return nil, fmt.Errorf("构建 sid 失败: %w", err)
+		}
+		defer tSid.Destroy()
+		inputValues["sid"] = tSid
+	}
+
 	outputValues, err := e.session.Run(inputValues)
 	if err != nil {
 		return nil, fmt.Errorf("piper 推理失败: %w", err)
@@ -132,4 +288,13 @@ func (e *Engine) Destroy() {
 	if e.session != nil {
 		e.session.Destroy()
 	}
+	if e.onnx != nil {
+		e.onnx.Destroy()
+	}
+}
+
+// Close 释放资源，实现 tts.Synthesizer 接口
+func (e *Engine) Close() error {
+	e.Destroy()
+	return nil
 }
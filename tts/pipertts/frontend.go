@@ -1,16 +1,18 @@
 package pipertts
 
 import (
-	"fmt"
+	"github.com/getcharzp/go-speech/tts"
 	"github.com/mozillazg/go-pinyin"
 	"regexp"
 	"strings"
 )
 
 // textToIds 将中文文本转换为 Piper 识别的 ID 序列
-func (e *Engine) textToIds(text string) []int64 {
+//
+// 无法映射的音素会被丢弃，记录到日志并写入 diag (可为 nil)
+func (e *Engine) textToIds(text string, diag *tts.Diagnostics) []int64 {
 	phonemes := e.toPhonemes(text)
-	return e.phonemesToIds(phonemes)
+	return e.phonemesToIds(phonemes, text, diag)
 }
 
 // toPhonemes 将中文文本转换为 Piper 识别的音素序列
@@ -81,7 +83,7 @@ func (e *Engine) toPhonemes(text string) string {
 }
 
 // phonemesToIds 将以空格分隔的音素字符串转为 ID 序列
-func (e *Engine) phonemesToIds(phonemesText string) []int64 {
+func (e *Engine) phonemesToIds(phonemesText string, text string, diag *tts.Diagnostics) []int64 {
 	ids := make([]int64, 0)
 
 	tokens := strings.Split(strings.TrimSpace(phonemesText), " ")
@@ -94,7 +96,8 @@ func (e *Engine) phonemesToIds(phonemesText string) []int64 {
 		if mappedIDs, ok := e.piperConfig.PhonemeIDMap[token]; ok {
 			ids = append(ids, mappedIDs...)
 		} else {
-			fmt.Printf("[WARN] 未找到音素映射: %s\n", token)
+			e.logger.Warn("未找到音素映射", "token", token, "text", text)
+			diag.AddDropped(token, text, "unknown_phoneme")
 		}
 	}
 
@@ -0,0 +1,48 @@
+package tts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SplitSentences 按句末标点与换行将文本切分为句子，标点保留在句尾
+//
+// 引擎逐句合成，既能避免长文本一次推理占用过多内存，也可以在句子之间响应取消。
+// 英文句号仅在其后为空白或文本结尾时切分，避免拆开小数与缩写
+func SplitSentences(text string) []string {
+	var sentences []string
+	runes := []rune(text)
+	start := 0
+	flush := func(end int) {
+		if s := strings.TrimSpace(string(runes[start:end])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end
+	}
+
+	for i, r := range runes {
+		switch r {
+		case '。', '！', '？', '；', '!', '?', ';', '\n':
+			// 连续的标点 (例如 "？！" 或 "……。") 归入同一句
+			if i+1 < len(runes) && isSentenceEnd(runes[i+1]) {
+				continue
+			}
+			flush(i + 1)
+		case '.':
+			if i+1 == len(runes) || unicode.IsSpace(runes[i+1]) {
+				flush(i + 1)
+			}
+		}
+	}
+	flush(len(runes))
+	return sentences
+}
+
+// isSentenceEnd 判断是否为句末标点
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '。', '！', '？', '；', '!', '?', ';':
+		return true
+	}
+	return false
+}
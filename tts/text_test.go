@@ -0,0 +1,22 @@
+package tts
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	cases := map[string][]string{
+		"":   nil,
+		"你好": {"你好"},
+		"你好。今天天气怎么样？！很好":     {"你好。", "今天天气怎么样？！", "很好"},
+		"Pi is 3.14. Right?": {"Pi is 3.14.", "Right?"},
+		"第一行\n\n第二行；第三行":     {"第一行", "第二行；", "第三行"},
+		"  前后空白  。  ":        {"前后空白  。"},
+	}
+	for text, want := range cases {
+		if got := SplitSentences(text); !slices.Equal(got, want) {
+			t.Errorf("SplitSentences(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
@@ -1 +1,84 @@
 package tts
+
+import (
+	"context"
+	"github.com/getcharzp/go-speech/audio"
+)
+
+// Synthesizer 语音合成引擎的统一接口
+//
+// melotts.Engine 与 pipertts.Engine 均实现了该接口，调用方可以在运行时选择引擎与音色
+type Synthesizer interface {
+	// Synthesize 将文本转换为语音数据 (float32 PCM)，采样率见 Info().SampleRate
+	Synthesize(text string, opt ...SynthesisOptions) ([]float32, error)
+	// SynthesizeContext 与 Synthesize 相同，ctx 取消或超时时在句子之间中止并返回 ctx.Err()
+	SynthesizeContext(ctx context.Context, text string, opt ...SynthesisOptions) ([]float32, error)
+	// SynthesizeToWav 将文本转换为 16 位 PCM WAV 格式的字节流
+	SynthesizeToWav(text string, opt ...SynthesisOptions) ([]byte, error)
+	// SynthesizeTo 将文本转换为指定编码、采样率与声道数的字节流，例如 audio.OutputMuLaw8k
+	SynthesizeTo(text string, format audio.OutputFormat, opt ...SynthesisOptions) ([]byte, error)
+	// Info 返回引擎的音频与音色信息
+	Info() Info
+	// Close 释放引擎持有的资源
+	Close() error
+}
+
+// SynthesisOptions 合成可选参数，零值表示使用引擎默认值
+type SynthesisOptions struct {
+	Speed       float32 // 语速调节，数值越大越快，1.0 为正常语速
+	Speaker     string  // 说话人名称，可选值见 Info().Speakers
+	NoiseScale  float32 // 噪声比例，影响发音的随机性
+	NoiseScaleW float32 // 时长噪声比例，影响韵律的随机性
+
+	// Diagnostics (可选) 非空时收集本次调用的诊断信息，例如被丢弃的字符
+	Diagnostics *Diagnostics
+}
+
+// Diagnostics 单次合成的诊断信息
+type Diagnostics struct {
+	Dropped []Dropped // 无法转换而被丢弃的字符、单词或音素
+}
+
+// Dropped 被丢弃的输入
+type Dropped struct {
+	Token  string // 被丢弃的字符、单词或音素
+	Text   string // 所在的文本
+	Reason string // 丢弃原因，"oov_char" 未登录字符, "unknown_phoneme" 未知音素
+}
+
+// AddDropped 记录被丢弃的输入，d 为 nil 时忽略
+func (d *Diagnostics) AddDropped(token, text, reason string) {
+	if d == nil {
+		return
+	}
+	d.Dropped = append(d.Dropped, Dropped{Token: token, Text: text, Reason: reason})
+}
+
+// Info 合成引擎信息
+type Info struct {
+	SampleRate int       // 输出音频采样率
+	Languages  []string  // 支持的语言，例如："zh", "en"
+	Speakers   []Speaker // 可选的说话人
+}
+
+// Speaker 说话人
+type Speaker struct {
+	ID   int64  // 模型内部的说话人 ID
+	Name string // 说话人名称
+}
+
+// FindSpeaker 根据名称查找说话人，名称为空时返回第一个说话人
+func (i Info) FindSpeaker(name string) (Speaker, bool) {
+	if len(i.Speakers) == 0 {
+		return Speaker{}, name == ""
+	}
+	if name == "" {
+		return i.Speakers[0], true
+	}
+	for _, s := range i.Speakers {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Speaker{}, false
+}
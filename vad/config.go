@@ -0,0 +1,75 @@
+package vad
+
+import (
+	"github.com/getcharzp/go-speech"
+	"io"
+	"log/slog"
+)
+
+const (
+	// engineName 引擎名称，用于注册、日志与错误
+	engineName = "vad"
+	// SampleRate 输入音频的采样率
+	SampleRate = 16000
+)
+
+// 模型类型
+const (
+	ModelSilero = "silero" // Silero VAD v4/v5
+	ModelFSMN   = "fsmn"   // FunASR FSMN-VAD
+)
+
+// Config 定义 VAD 模型与语音段检测的配置参数
+type Config struct {
+	// 必填参数
+	OnnxRuntimeLibPath string `json:"onnx_runtime_lib_path"` // onnxruntime.dll (或 .so, .dylib) 的路径
+	ModelPath          string `json:"model_path"`            // ONNX 模型路径，根据模型的输入自动识别 Silero 或 FSMN-VAD
+	CMVNPath           string `json:"cmvn_path"`             // FSMN-VAD 的 am.mvn 路径，Silero 不需要
+
+	// 语音段检测参数
+	Threshold            float32 `json:"threshold"`               // (可选) 进入语音的概率阈值，默认 0.5
+	NegThreshold         float32 `json:"neg_threshold"`           // (可选) 退出语音的概率阈值，默认 Threshold - 0.15
+	MinSpeechDurationMs  int     `json:"min_speech_duration_ms"`  // (可选) 最短语音时长，更短的语音段被丢弃，默认 250
+	MinSilenceDurationMs int     `json:"min_silence_duration_ms"` // (可选) 结束语音段所需的最短静音时长，默认 500
+	SpeechPadMs          int     `json:"speech_pad_ms"`           // (可选) 语音段两侧保留的时长，默认 100
+	MaxSpeechDurationMs  int     `json:"max_speech_duration_ms"`  // (可选) 最长语音时长，超出时在静音处切分，0 表示不限制
+
+	// 可选参数
+	UseCuda                bool            `json:"use_cuda"`                 // (可选) 是否启用 CUDA
+	NumThreads             int             `json:"num_threads"`              // (可选) ONNX 线程数, 默认由CPU核心数决定
+	EnableCpuMemArena      bool            `json:"enable_cpu_mem_arena"`     // (可选) 是否启用内存池
+	GraphOptimizationLevel string          `json:"graph_optimization_level"` // (可选) 图优化级别: disable, basic, extended, all
+	ExecutionMode          string          `json:"execution_mode"`           // (可选) 执行模式: sequential, parallel
+	InterOpNumThreads      int             `json:"inter_op_num_threads"`     // (可选) 算子间并行线程数，仅 parallel 模式生效
+	DisableMemPattern      bool            `json:"disable_mem_pattern"`      // (可选) 禁用内存模式优化
+	OptimizedModelDir      string          `json:"optimized_model_dir"`      // (可选) 优化后模型的缓存目录，用于缩短冷启动时间
+	Runtime                *speech.Runtime `json:"-"`                        // (可选) 共享的 ONNX 运行时，设置后忽略 OnnxRuntimeLibPath
+	Logger                 *slog.Logger    `json:"-"`                        // (可选) 日志记录器，默认使用 slog.Default()
+	Observer               speech.Observer `json:"-"`                        // (可选) 推理观测者，每次 Accept 与 Split 调用上报一次
+}
+
+// DefaultConfig 返回一套默认的配置 (基于常见的目录结构，使用 Silero VAD)
+func DefaultConfig() Config {
+	return Config{
+		OnnxRuntimeLibPath:   speech.DefaultLibraryPath(),
+		ModelPath:            "./vad_weights/silero_vad.onnx",
+		Threshold:            0.5,
+		MinSpeechDurationMs:  250,
+		MinSilenceDurationMs: 500,
+		SpeechPadMs:          100,
+	}
+}
+
+func init() {
+	speech.Register(engineName, func(spec speech.EngineSpec) (io.Closer, error) {
+		cfg := DefaultConfig()
+		if err := spec.Decode(&cfg); err != nil {
+			return nil, err
+		}
+		engine, err := NewEngine(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return engine, nil
+	})
+}
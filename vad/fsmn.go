@@ -0,0 +1,198 @@
+package vad
+
+import (
+	"fmt"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/internal/fbank"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"github.com/up-zero/gotool/mediautil"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// fsmnLFR FSMN-VAD 的 LFR 拼帧数，步长为 1
+	fsmnLFR = 5
+	// FSMN 每层缓存的初始形状 [1, fsmnCacheProj, fsmnCacheOrder, 1]
+	fsmnCacheProj  = 128
+	fsmnCacheOrder = 19
+	// fsmnScale FunASR 的前端按 16bit 整数范围计算特征
+	fsmnScale = 32768
+)
+
+// fsmn FunASR FSMN-VAD，每 10ms 一帧，语音概率为 1 - 静音类别的概率
+type fsmn struct {
+	session *ort.Session
+	negMean []float32
+	invStd  []float32
+	caches  [][]float32 // in_cache0, in_cache1, ...
+	shapes  [][]int64
+
+	fftBuffer []complex128
+	last      float32     // 预加重的上一个样本
+	wave      []float32   // 预加重后尚未成帧的样本
+	fbank     [][]float32 // 尚未拼帧的 FilterBank 特征
+	padded    bool        // 是否已在开头补帧
+}
+
+// newFSMN 校验模型输入输出，加载 CMVN 并创建 FSMN-VAD
+func newFSMN(session *ort.Session, cmvnPath string) (*fsmn, error) {
+	var caches []string
+	for _, name := range session.InputNames {
+		if strings.HasPrefix(name, "in_cache") {
+			caches = append(caches, name)
+		}
+	}
+	outputs := []string{"logits"}
+	for i := range caches {
+		outputs = append(outputs, "out_cache"+strconv.Itoa(i))
+	}
+	inputs := append([]string{"speech"}, caches...)
+	if err := speech.CheckSession(session, inputs, outputs); err != nil {
+		session.Destroy()
+		return nil, fmt.Errorf("FSMN-VAD: %w", err)
+	}
+
+	if cmvnPath == "" {
+		session.Destroy()
+		return nil, fmt.Errorf("FSMN-VAD 需要配置 cmvn_path")
+	}
+	negMean, invStd, err := fbank.LoadCMVN(cmvnPath)
+	if err != nil {
+		session.Destroy()
+		return nil, fmt.Errorf("加载 CMVN 失败: %w", err)
+	}
+	if len(negMean) != fbank.MelBins*fsmnLFR || len(invStd) != len(negMean) {
+		session.Destroy()
+		return nil, fmt.Errorf("CMVN 维度错误: %d", len(negMean))
+	}
+
+	f := &fsmn{
+		session:   session,
+		negMean:   negMean,
+		invStd:    invStd,
+		caches:    make([][]float32, len(caches)),
+		shapes:    make([][]int64, len(caches)),
+		fftBuffer: make([]complex128, fbank.FFTSize),
+	}
+	f.reset()
+	return f, nil
+}
+
+func (f *fsmn) hop() int {
+	return fbank.FrameShift
+}
+
+func (f *fsmn) reset() {
+	for i := range f.caches {
+		f.shapes[i] = []int64{1, fsmnCacheProj, fsmnCacheOrder, 1}
+		f.caches[i] = make([]float32, fsmnCacheProj*fsmnCacheOrder)
+	}
+	f.last, f.padded = 0, false
+	f.wave, f.fbank = f.wave[:0], f.fbank[:0]
+}
+
+func (f *fsmn) destroy() {
+	f.session.Destroy()
+}
+
+func (f *fsmn) accept(samples []float32) ([]float32, error) {
+	for _, v := range samples {
+		v *= fsmnScale
+		f.wave = append(f.wave, v-fbank.PreEmphasis*f.last)
+		f.last = v
+	}
+	start := 0
+	for ; start+fbank.FrameLen <= len(f.wave); start += fbank.FrameShift {
+		f.fbank = append(f.fbank, fbank.Frame(f.wave[start:start+fbank.FrameLen], f.fftBuffer))
+	}
+	f.wave = f.wave[:copy(f.wave, f.wave[start:])]
+
+	// 与 FunASR 一致，开头重复第一帧 (fsmnLFR-1)/2 次
+	if !f.padded && len(f.fbank) > 0 {
+		f.fbank = append(slices.Repeat([][]float32{f.fbank[0]}, (fsmnLFR-1)/2), f.fbank...)
+		f.padded = true
+	}
+	return f.infer(false)
+}
+
+// flush 用最后一帧补齐剩余的拼帧
+func (f *fsmn) flush() ([]float32, error) {
+	return f.infer(true)
+}
+
+// infer 将可以拼帧的特征送入模型，更新 FSMN 缓存
+func (f *fsmn) infer(final bool) ([]float32, error) {
+	var frames []float32
+	numFrames := 0
+	for len(f.fbank) >= fsmnLFR || final && len(f.fbank) > (fsmnLFR-1)/2 {
+		frame := make([]float32, 0, fbank.MelBins*fsmnLFR)
+		for j := 0; j < fsmnLFR; j++ {
+			frame = append(frame, f.fbank[min(j, len(f.fbank)-1)]...)
+		}
+		mediautil.ApplyCMVN([][]float32{frame}, f.negMean, f.invStd)
+		frames = append(frames, frame...)
+		numFrames++
+		f.fbank = f.fbank[1:]
+	}
+	if final {
+		f.fbank = f.fbank[:0]
+	}
+	if numFrames == 0 {
+		return nil, nil
+	}
+
+	tSpeech, err := ort.NewTensor([]int64{1, int64(numFrames), fbank.MelBins * fsmnLFR}, frames)
+	if err != nil {
+		return nil, fmt.Errorf("创建 speech tensor 失败: %w", err)
+	}
+	defer tSpeech.Destroy()
+	inputs := map[string]*ort.Value{"speech": tSpeech}
+	for i, cache := range f.caches {
+		t, err := ort.NewTensor(f.shapes[i], cache)
+		if err != nil {
+			return nil, fmt.Errorf("创建 in_cache%d tensor 失败: %w", i, err)
+		}
+		defer t.Destroy()
+		inputs["in_cache"+strconv.Itoa(i)] = t
+	}
+
+	outputs, err := f.session.Run(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("推理运行失败: %w", err)
+	}
+	defer func() {
+		for _, v := range outputs {
+			v.Destroy()
+		}
+	}()
+
+	for i := range f.caches {
+		out := outputs["out_cache"+strconv.Itoa(i)]
+		data, err := ort.GetTensorData[float32](out)
+		if err != nil {
+			return nil, fmt.Errorf("获取 out_cache%d 失败: %w", i, err)
+		}
+		shape, err := out.GetShape()
+		if err != nil {
+			return nil, fmt.Errorf("获取 out_cache%d 形状失败: %w", i, err)
+		}
+		f.caches[i], f.shapes[i] = slices.Clone(data), shape
+	}
+
+	logits, err := ort.GetTensorData[float32](outputs["logits"])
+	if err != nil {
+		return nil, fmt.Errorf("获取输出数据失败: %w", err)
+	}
+	classes := len(logits) / numFrames
+	if classes == 0 {
+		return nil, fmt.Errorf("输出维度错误: %d", len(logits))
+	}
+	probs := make([]float32, numFrames)
+	for t := range probs {
+		// 第 0 类为静音
+		probs[t] = 1 - logits[t*classes]
+	}
+	return probs, nil
+}
@@ -0,0 +1,122 @@
+package vad
+
+import "time"
+
+// segmenter 根据逐帧的语音概率检测语音段，状态机与 Silero VAD 的 get_speech_timestamps 一致
+//
+// 概率高于 threshold 时进入语音，低于 negThreshold 且持续 minSilence 后结束语音，
+// 短于 minSpeech 的语音段被丢弃，超过 maxSpeech 的语音段在最近的静音处切分，没有静音时强制切分
+type segmenter struct {
+	threshold    float32
+	negThreshold float32
+	minSpeech    int // 以下均以样本数为单位
+	minSilence   int
+	pad          int
+	maxSpeech    int // 0 表示不限制
+
+	pos       int  // 已处理的样本数
+	triggered bool // 是否处于语音段
+	start     int  // 当前语音段的起点
+	tempEnd   int  // 候选的语音段终点 (静音起点)，-1 表示没有
+	lastEnd   int  // 上一个输出的语音段 (含 padding) 的终点
+}
+
+// span 样本区间 [start, end)，已包含 padding
+type span struct {
+	start, end int
+}
+
+// newSegmenter 按毫秒参数创建状态机
+func newSegmenter(cfg Config) *segmenter {
+	samples := func(ms int) int { return ms * SampleRate / 1000 }
+	s := &segmenter{
+		threshold:    cfg.Threshold,
+		negThreshold: cfg.NegThreshold,
+		minSpeech:    samples(cfg.MinSpeechDurationMs),
+		minSilence:   samples(cfg.MinSilenceDurationMs),
+		pad:          samples(cfg.SpeechPadMs),
+		maxSpeech:    samples(cfg.MaxSpeechDurationMs),
+	}
+	s.reset()
+	return s
+}
+
+// reset 清空状态
+func (s *segmenter) reset() {
+	s.pos, s.triggered, s.start, s.tempEnd, s.lastEnd = 0, false, 0, -1, 0
+}
+
+// push 输入一帧 (hop 个样本) 的语音概率，返回结束的语音段
+func (s *segmenter) push(prob float32, hop int) []span {
+	var spans []span
+	frameStart := s.pos
+	s.pos += hop
+
+	if prob >= s.threshold {
+		s.tempEnd = -1
+		if !s.triggered {
+			s.triggered, s.start = true, frameStart
+		}
+	}
+
+	if s.triggered && s.maxSpeech > 0 && s.pos-s.start > s.maxSpeech {
+		// 优先在候选静音处切分，否则强制切分
+		cut := s.pos
+		if s.tempEnd > s.start {
+			cut = s.tempEnd
+		}
+		spans = append(spans, s.emit(s.start, cut))
+		s.start, s.tempEnd = cut, -1
+		s.triggered = prob >= s.negThreshold
+	}
+
+	if s.triggered && prob < s.negThreshold {
+		if s.tempEnd < 0 {
+			s.tempEnd = frameStart
+		}
+		if s.pos-s.tempEnd >= s.minSilence {
+			if s.tempEnd-s.start >= s.minSpeech {
+				spans = append(spans, s.emit(s.start, s.tempEnd))
+			}
+			s.triggered, s.tempEnd = false, -1
+		}
+	}
+	return spans
+}
+
+// flush 结束输入，返回尚未结束的语音段
+func (s *segmenter) flush() []span {
+	if !s.triggered {
+		return nil
+	}
+	end := s.pos
+	if s.tempEnd >= 0 {
+		end = s.tempEnd
+	}
+	s.triggered, s.tempEnd = false, -1
+	if end-s.start < s.minSpeech {
+		return nil
+	}
+	return []span{s.emit(s.start, end)}
+}
+
+// emit 为语音段加上 padding，起点不早于上一个语音段的终点，终点不超过已输入的样本
+func (s *segmenter) emit(start, end int) span {
+	sp := span{start: max(start-s.pad, s.lastEnd, 0), end: min(end+s.pad, s.pos)}
+	s.lastEnd = sp.end
+	return sp
+}
+
+// keepFrom 返回仍可能被后续语音段引用的最早样本位置，之前的样本可以丢弃
+func (s *segmenter) keepFrom() int {
+	from := s.pos - s.pad
+	if s.triggered {
+		from = s.start - s.pad
+	}
+	return max(from, s.lastEnd, 0)
+}
+
+// toDuration 样本数转换为时长
+func toDuration(samples int) time.Duration {
+	return time.Duration(samples) * time.Second / SampleRate
+}
@@ -0,0 +1,144 @@
+package vad
+
+import (
+	"fmt"
+	"github.com/getcharzp/go-speech"
+	ort "github.com/getcharzp/onnxruntime_purego"
+	"slices"
+)
+
+const (
+	// sileroWindow Silero VAD 在 16KHz 下每次推理的样本数 (32ms)
+	sileroWindow = 512
+	// sileroContext v5 模型在每个窗口前拼接的上一窗口末尾样本数
+	sileroContext = 64
+)
+
+// silero Silero VAD，v5 使用 state [2, 1, 128] 并拼接上下文，v4 使用 h/c [2, 1, 64]
+type silero struct {
+	session *ort.Session
+	v5      bool
+	state   [][]float32 // v5: [state]，v4: [h, c]
+	context []float32   // v5 上一窗口末尾的样本
+	pending []float32   // 不足一个窗口的样本
+}
+
+// newSilero 校验模型输入输出并创建 Silero VAD
+func newSilero(session *ort.Session) (*silero, error) {
+	s := &silero{session: session, v5: slices.Contains(session.InputNames, "state")}
+	inputs, outputs := []string{"input", "sr", "h", "c"}, []string{"output", "hn", "cn"}
+	if s.v5 {
+		inputs, outputs = []string{"input", "sr", "state"}, []string{"output", "stateN"}
+	}
+	if err := speech.CheckSession(session, inputs, outputs); err != nil {
+		session.Destroy()
+		return nil, fmt.Errorf("Silero VAD: %w", err)
+	}
+	s.reset()
+	return s, nil
+}
+
+func (s *silero) hop() int {
+	return sileroWindow
+}
+
+func (s *silero) reset() {
+	if s.v5 {
+		s.state = [][]float32{make([]float32, 2*128)}
+	} else {
+		s.state = [][]float32{make([]float32, 2*64), make([]float32, 2*64)}
+	}
+	s.context = make([]float32, sileroContext)
+	s.pending = s.pending[:0]
+}
+
+func (s *silero) destroy() {
+	s.session.Destroy()
+}
+
+func (s *silero) accept(samples []float32) ([]float32, error) {
+	s.pending = append(s.pending, samples...)
+	var probs []float32
+	n := 0
+	for ; n+sileroWindow <= len(s.pending); n += sileroWindow {
+		p, err := s.infer(s.pending[n : n+sileroWindow])
+		if err != nil {
+			return nil, err
+		}
+		probs = append(probs, p)
+	}
+	s.pending = s.pending[:copy(s.pending, s.pending[n:])]
+	return probs, nil
+}
+
+// flush 最后不足一个窗口的样本补零后推理
+func (s *silero) flush() ([]float32, error) {
+	if len(s.pending) == 0 {
+		return nil, nil
+	}
+	window := make([]float32, sileroWindow)
+	copy(window, s.pending)
+	s.pending = s.pending[:0]
+	p, err := s.infer(window)
+	if err != nil {
+		return nil, err
+	}
+	return []float32{p}, nil
+}
+
+// infer 对一个窗口推理，更新 RNN 状态
+func (s *silero) infer(window []float32) (float32, error) {
+	input := window
+	if s.v5 {
+		input = append(slices.Clone(s.context), window...)
+		copy(s.context, window[len(window)-sileroContext:])
+	}
+
+	tInput, err := ort.NewTensor([]int64{1, int64(len(input))}, input)
+	if err != nil {
+		return 0, fmt.Errorf("创建 input tensor 失败: %w", err)
+	}
+	defer tInput.Destroy()
+	tSR, err := ort.NewTensor([]int64{}, []int64{SampleRate})
+	if err != nil {
+		return 0, fmt.Errorf("创建 sr tensor 失败: %w", err)
+	}
+	defer tSR.Destroy()
+	inputs := map[string]*ort.Value{"input": tInput, "sr": tSR}
+
+	stateIn, stateOut := []string{"h", "c"}, []string{"hn", "cn"}
+	if s.v5 {
+		stateIn, stateOut = []string{"state"}, []string{"stateN"}
+	}
+	for i, name := range stateIn {
+		t, err := ort.NewTensor([]int64{2, 1, int64(len(s.state[i]) / 2)}, s.state[i])
+		if err != nil {
+			return 0, fmt.Errorf("创建 %s tensor 失败: %w", name, err)
+		}
+		defer t.Destroy()
+		inputs[name] = t
+	}
+
+	outputs, err := s.session.Run(inputs)
+	if err != nil {
+		return 0, fmt.Errorf("推理运行失败: %w", err)
+	}
+	defer func() {
+		for _, v := range outputs {
+			v.Destroy()
+		}
+	}()
+
+	for i, name := range stateOut {
+		data, err := ort.GetTensorData[float32](outputs[name])
+		if err != nil || len(data) != len(s.state[i]) {
+			return 0, fmt.Errorf("获取 %s 失败: %v", name, err)
+		}
+		copy(s.state[i], data)
+	}
+	prob, err := ort.GetTensorData[float32](outputs["output"])
+	if err != nil || len(prob) == 0 {
+		return 0, fmt.Errorf("获取输出数据失败: %v", err)
+	}
+	return prob[0], nil
+}
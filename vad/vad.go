@@ -0,0 +1,282 @@
+// Package vad 提供语音活动检测 (Voice Activity Detection)，支持 Silero VAD 与 FunASR FSMN-VAD 的 ONNX 模型
+package vad
+
+import (
+	"context"
+	"fmt"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"github.com/up-zero/gotool/convertutil"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Segment 语音段
+type Segment struct {
+	Start   time.Duration // 起始时间，已包含 padding
+	End     time.Duration // 结束时间，已包含 padding
+	Samples []float32     // 语音段的 16KHz 单声道样本
+}
+
+// Duration 返回语音段时长
+func (s Segment) Duration() time.Duration {
+	return s.End - s.Start
+}
+
+// splitBlock Split 每次输入模型的样本数
+const splitBlock = 30 * SampleRate
+
+// model 逐帧计算语音概率的模型
+type model interface {
+	// hop 每个概率对应的样本数
+	hop() int
+	// accept 输入任意长度的样本，返回新计算出的概率，不足一次推理的样本会被缓存
+	accept(samples []float32) ([]float32, error)
+	// flush 处理缓存的剩余样本
+	flush() ([]float32, error)
+	// reset 清空模型状态
+	reset()
+	// destroy 释放会话
+	destroy()
+}
+
+// Engine 封装了 VAD 模型的 ONNX 运行时与语音段检测状态
+//
+// Engine 保存流式检测的状态，不是并发安全的，并发检测请使用 speech.Pool 持有多个实例
+type Engine struct {
+	onnx      *speech.OnnxConfig
+	model     model
+	modelName string
+	seg       *segmenter
+
+	buf      []float32 // 仍可能被语音段引用的样本
+	bufStart int       // buf[0] 在整个音频中的样本序号
+	received int       // 已输入模型的样本数
+
+	logger   *slog.Logger
+	observer speech.Observer
+}
+
+// NewEngine 初始化 VAD 引擎，根据模型的输入自动识别 Silero VAD 或 FSMN-VAD
+//
+// 失败时返回的错误满足 errors.Is(err, speech.ErrModelLoad)
+func NewEngine(cfg Config) (_ *Engine, err error) {
+	defer func() { err = speech.WrapError(speech.ErrModelLoad, engineName, err) }()
+
+	if cfg.Threshold <= 0 || cfg.Threshold >= 1 {
+		return nil, fmt.Errorf("threshold 必须在 (0, 1) 之间: %v", cfg.Threshold)
+	}
+	if cfg.NegThreshold == 0 {
+		cfg.NegThreshold = max(cfg.Threshold-0.15, 0.01)
+	}
+	if cfg.NegThreshold > cfg.Threshold {
+		return nil, fmt.Errorf("neg_threshold 不能大于 threshold: %v > %v", cfg.NegThreshold, cfg.Threshold)
+	}
+	if min(cfg.MinSpeechDurationMs, cfg.MinSilenceDurationMs, cfg.SpeechPadMs, cfg.MaxSpeechDurationMs) < 0 {
+		return nil, fmt.Errorf("时长参数不能为负数")
+	}
+
+	oc := new(speech.OnnxConfig)
+	_ = convertutil.CopyProperties(cfg, oc)
+
+	// 初始化 ONNX
+	if err := oc.New(); err != nil {
+		return nil, err
+	}
+	engine := &Engine{
+		onnx:     oc,
+		seg:      newSegmenter(cfg),
+		logger:   speech.EngineLogger(cfg.Logger, engineName),
+		observer: cfg.Observer,
+	}
+
+	session, err := oc.NewSession(cfg.ModelPath)
+	if err != nil {
+		engine.Destroy()
+		return nil, fmt.Errorf("创建 ONNX 会话失败: %w", err)
+	}
+	switch {
+	case slices.Contains(session.InputNames, "state") || slices.Contains(session.InputNames, "h"):
+		engine.modelName = ModelSilero
+		engine.model, err = newSilero(session)
+	case slices.Contains(session.InputNames, "speech"):
+		engine.modelName = ModelFSMN
+		engine.model, err = newFSMN(session, cfg.CMVNPath)
+	default:
+		session.Destroy()
+		err = fmt.Errorf("无法识别的 VAD 模型，输入: %v", session.InputNames)
+	}
+	if err != nil {
+		engine.Destroy()
+		return nil, err
+	}
+	engine.logger.Debug("加载 VAD 模型", "model", engine.modelName)
+	return engine, nil
+}
+
+// Destroy 释放相关资源
+func (e *Engine) Destroy() {
+	if e.model != nil {
+		e.model.destroy()
+	}
+	if e.onnx != nil {
+		e.onnx.Destroy()
+	}
+}
+
+// Close 释放相关资源，实现 io.Closer 接口
+func (e *Engine) Close() error {
+	e.Destroy()
+	return nil
+}
+
+// Model 返回模型类型: ModelSilero 或 ModelFSMN
+func (e *Engine) Model() string {
+	return e.modelName
+}
+
+// Segments 检测整段音频中的语音段
+//
+// 调用前会清空流式检测的状态
+//
+// # Params:
+//
+//	samples: 采样率为 16KHz 的单声道音频数据，范围 [-1, 1]
+//
+// # Examples:
+//
+//	segments, err := engine.Segments(samples)
+//	for _, seg := range segments {
+//		fmt.Println(seg.Start, seg.End)
+//	}
+func (e *Engine) Segments(samples []float32) ([]Segment, error) {
+	e.Reset()
+	segments, err := e.Accept(samples)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := e.Flush()
+	if err != nil {
+		return nil, err
+	}
+	return append(segments, tail...), nil
+}
+
+// Split 检测整段音频中的语音段，实现 asr.Splitter 接口，用于长音频识别
+//
+// 与 Segments 不同，Split 只计算时间范围，不缓存与复制样本，音频按固定大小分块输入模型。
+// 调用前后均会清空流式检测的状态
+func (e *Engine) Split(samples []float32) (_ []asr.Span, err error) {
+	rec := speech.StartCall(e.observer, engineName, speech.OpDetect)
+	defer func() { rec.Finish(context.Background(), err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	rec.SetAudio(len(samples), SampleRate)
+	e.Reset()
+	defer e.Reset()
+
+	var spans []span
+	for i := 0; i < len(samples); i += splitBlock {
+		block := samples[i:min(i+splitBlock, len(samples))]
+		probs, err := e.model.accept(block)
+		if err != nil {
+			return nil, err
+		}
+		rec.Stage("inference")
+		e.received += len(block)
+		spans = append(spans, e.spans(probs, false)...)
+		rec.Stage("segment")
+	}
+	probs, err := e.model.flush()
+	if err != nil {
+		return nil, err
+	}
+	rec.Stage("inference")
+	spans = append(spans, e.spans(probs, true)...)
+	rec.Stage("segment")
+
+	result := make([]asr.Span, len(spans))
+	for i, sp := range spans {
+		result[i] = asr.Span{Start: toDuration(sp.start), End: toDuration(sp.end)}
+	}
+	return result, nil
+}
+
+// Accept 流式输入音频帧，返回已经结束的语音段
+//
+// # Params:
+//
+//	frame: 采样率为 16KHz 的单声道音频数据，长度任意
+func (e *Engine) Accept(frame []float32) (_ []Segment, err error) {
+	rec := speech.StartCall(e.observer, engineName, speech.OpDetect)
+	defer func() { rec.Finish(context.Background(), err) }()
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	rec.SetAudio(len(frame), SampleRate)
+
+	e.buf = append(e.buf, frame...)
+	e.received += len(frame)
+	probs, err := e.model.accept(frame)
+	if err != nil {
+		return nil, err
+	}
+	rec.Stage("inference")
+	segments := e.push(probs, false)
+	rec.Stage("segment")
+	return segments, nil
+}
+
+// Flush 结束输入，返回尚未结束的语音段，随后清空状态
+func (e *Engine) Flush() (_ []Segment, err error) {
+	defer func() { err = speech.WrapError(speech.ErrInference, engineName, err) }()
+	defer e.Reset()
+	probs, err := e.model.flush()
+	if err != nil {
+		return nil, err
+	}
+	return e.push(probs, true), nil
+}
+
+// Reset 清空流式检测的状态
+func (e *Engine) Reset() {
+	e.model.reset()
+	e.seg.reset()
+	e.buf, e.bufStart, e.received = e.buf[:0], 0, 0
+}
+
+// IsSpeech 返回当前是否处于语音段中
+func (e *Engine) IsSpeech() bool {
+	return e.seg.triggered
+}
+
+// spans 将概率输入状态机，返回已经结束的语音段范围
+func (e *Engine) spans(probs []float32, final bool) []span {
+	var spans []span
+	hop := e.model.hop()
+	for _, p := range probs {
+		// 模型按整帧输出概率，最后一帧可能超出实际样本
+		spans = append(spans, e.seg.push(p, max(min(hop, e.received-e.seg.pos), 0))...)
+	}
+	if final {
+		spans = append(spans, e.seg.flush()...)
+	}
+	return spans
+}
+
+// push 将概率输入状态机，截取结束的语音段并丢弃不再需要的样本
+func (e *Engine) push(probs []float32, final bool) []Segment {
+	spans := e.spans(probs, final)
+	segments := make([]Segment, 0, len(spans))
+	for _, sp := range spans {
+		segments = append(segments, Segment{
+			Start:   toDuration(sp.start),
+			End:     toDuration(sp.end),
+			Samples: slices.Clone(e.buf[sp.start-e.bufStart : sp.end-e.bufStart]),
+		})
+	}
+
+	if drop := e.seg.keepFrom() - e.bufStart; drop > 0 {
+		e.buf = e.buf[:copy(e.buf, e.buf[drop:])]
+		e.bufStart += drop
+	}
+	return segments
+}
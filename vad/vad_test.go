@@ -0,0 +1,245 @@
+package vad
+
+import (
+	"context"
+	"github.com/getcharzp/go-speech"
+	"github.com/getcharzp/go-speech/asr"
+	"testing"
+	"time"
+)
+
+// fakeModel 将样本的绝对值按帧取最大值作为语音概率
+type fakeModel struct {
+	pending []float32
+}
+
+func (m *fakeModel) hop() int { return 160 }
+
+func (m *fakeModel) accept(samples []float32) ([]float32, error) {
+	m.pending = append(m.pending, samples...)
+	var probs []float32
+	for len(m.pending) >= m.hop() {
+		probs = append(probs, m.prob(m.pending[:m.hop()]))
+		m.pending = m.pending[m.hop():]
+	}
+	return probs, nil
+}
+
+func (m *fakeModel) flush() ([]float32, error) {
+	if len(m.pending) == 0 {
+		return nil, nil
+	}
+	p := m.prob(m.pending)
+	m.pending = nil
+	return []float32{p}, nil
+}
+
+func (m *fakeModel) prob(frame []float32) float32 {
+	var p float32
+	for _, v := range frame {
+		p = max(p, v, -v)
+	}
+	return p
+}
+
+func (m *fakeModel) reset()   { m.pending = nil }
+func (m *fakeModel) destroy() {}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.NegThreshold = 0.35
+	return cfg
+}
+
+// fill 在 [from, to) 秒内写入幅度为 v 的样本
+func fill(samples []float32, from, to float64, v float32) {
+	for i := int(from * SampleRate); i < int(to*SampleRate); i++ {
+		samples[i] = v
+	}
+}
+
+func TestSegmenter(t *testing.T) {
+	cases := []struct {
+		name  string
+		probs []float32 // 每帧 10ms
+		cfg   func(*Config)
+		want  []span
+	}{
+		{
+			name:  "padding",
+			probs: frames(0, 50, 0.9, 100, 0, 100),
+			want:  []span{{start: 6400, end: 25600}},
+		},
+		{
+			name:  "短于 minSpeech 的语音被丢弃",
+			probs: frames(0, 50, 0.9, 20, 0, 100),
+		},
+		{
+			name:  "短暂静音不结束语音段",
+			probs: frames(0.9, 50, 0.1, 30, 0.9, 50, 0, 100),
+			want:  []span{{start: 0, end: 22400}},
+		},
+		{
+			name:  "高于 negThreshold 的概率保持语音",
+			probs: frames(0.9, 50, 0.4, 100, 0.1, 100),
+			want:  []span{{start: 0, end: 25600}},
+		},
+		{
+			name:  "超过 maxSpeech 时强制切分",
+			probs: frames(0.9, 250, 0, 100),
+			cfg:   func(c *Config) { c.MaxSpeechDurationMs = 1000 },
+			want:  []span{{start: 0, end: 16160}, {start: 16160, end: 32320}, {start: 32320, end: 41600}},
+		},
+		{
+			name:  "未结束的语音段在 flush 时输出",
+			probs: frames(0, 10, 0.9, 50),
+			want:  []span{{start: 0, end: 9600}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := testConfig()
+			if c.cfg != nil {
+				c.cfg(&cfg)
+			}
+			s := newSegmenter(cfg)
+			var got []span
+			for _, p := range c.probs {
+				got = append(got, s.push(p, 160)...)
+			}
+			got = append(got, s.flush()...)
+			if len(got) != len(c.want) {
+				t.Fatalf("spans: %v, want %v", got, c.want)
+			}
+			for i := range got {
+				if got[i] != c.want[i] {
+					t.Fatalf("spans: %v, want %v", got, c.want)
+				}
+			}
+		})
+	}
+}
+
+// frames 按 (概率, 帧数) 对生成概率序列
+func frames(pairs ...float32) []float32 {
+	var probs []float32
+	for i := 0; i < len(pairs); i += 2 {
+		for range int(pairs[i+1]) {
+			probs = append(probs, pairs[i])
+		}
+	}
+	return probs
+}
+
+func TestEngineAccept(t *testing.T) {
+	samples := make([]float32, 6*SampleRate)
+	fill(samples, 0.5, 1.5, 0.9)
+	fill(samples, 3, 4, 0.8)
+	fill(samples, 5.5, 6, 0.7)
+
+	e := &Engine{model: &fakeModel{}, seg: newSegmenter(testConfig())}
+	var segments []Segment
+	// 以不规则的块流式输入
+	for i := 0; i < len(samples); i += 1234 {
+		got, err := e.Accept(samples[i:min(i+1234, len(samples))])
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments = append(segments, got...)
+		if len(e.buf) > 2*SampleRate {
+			t.Fatalf("缓存未释放: %d", len(e.buf))
+		}
+	}
+	tail, err := e.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments = append(segments, tail...)
+
+	want := []struct {
+		start, end time.Duration
+		amp        float32
+	}{
+		{400 * time.Millisecond, 1600 * time.Millisecond, 0.9},
+		{2900 * time.Millisecond, 4100 * time.Millisecond, 0.8},
+		{5400 * time.Millisecond, 6 * time.Second, 0.7},
+	}
+	if len(segments) != len(want) {
+		t.Fatalf("segments: %d", len(segments))
+	}
+	for i, seg := range segments {
+		if seg.Start != want[i].start || seg.End != want[i].end {
+			t.Fatalf("segment %d: %v - %v", i, seg.Start, seg.End)
+		}
+		if len(seg.Samples) != int(seg.Duration()*SampleRate/time.Second) {
+			t.Fatalf("segment %d samples: %d", i, len(seg.Samples))
+		}
+		mid := seg.Samples[len(seg.Samples)/2]
+		if mid != want[i].amp {
+			t.Fatalf("segment %d: mid sample %v", i, mid)
+		}
+	}
+	if e.IsSpeech() || len(e.buf) != 0 {
+		t.Fatal("Flush 后状态未清空")
+	}
+}
+
+var _ asr.Splitter = (*Engine)(nil)
+
+func TestEngineSplit(t *testing.T) {
+	samples := make([]float32, 70*SampleRate)
+	fill(samples, 0.5, 1.5, 0.9)
+	fill(samples, 29.5, 31, 0.8)
+	fill(samples, 65, 70, 0.7)
+
+	e := &Engine{model: &fakeModel{}, seg: newSegmenter(testConfig())}
+	segments, err := e.Segments(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = &Engine{model: &fakeModel{}, seg: newSegmenter(testConfig())}
+	spans, err := e.Split(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 3 || len(spans) != len(segments) {
+		t.Fatalf("spans: %d, segments: %d", len(spans), len(segments))
+	}
+	for i, sp := range spans {
+		if sp.Start != segments[i].Start || sp.End != segments[i].End {
+			t.Fatalf("span %d: %v - %v, want %v - %v", i, sp.Start, sp.End, segments[i].Start, segments[i].End)
+		}
+	}
+	if cap(e.buf) != 0 {
+		t.Fatalf("Split 不应缓存样本: %d", cap(e.buf))
+	}
+}
+
+func TestEngineObserver(t *testing.T) {
+	var calls []speech.CallStats
+	e := &Engine{
+		model: &fakeModel{},
+		seg:   newSegmenter(testConfig()),
+		observer: speech.ObserverFunc(func(_ context.Context, stats speech.CallStats) {
+			calls = append(calls, stats)
+		}),
+	}
+	samples := make([]float32, SampleRate)
+	if _, err := e.Accept(samples); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Split(samples); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("calls: %d", len(calls))
+	}
+	for _, c := range calls {
+		if c.Engine != engineName || c.Op != speech.OpDetect || c.AudioDuration != time.Second {
+			t.Fatalf("stats: %+v", c)
+		}
+		if len(c.Stages) != 2 || c.Stages[0].Name != "inference" || c.Stages[1].Name != "segment" {
+			t.Fatalf("stages: %+v", c.Stages)
+		}
+	}
+}